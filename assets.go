@@ -0,0 +1,174 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/kataras/iris/utils"
+)
+
+const (
+	// AssetFuncName is the name of the template func which returns the fingerprinted url of an asset, i.e {{ asset "css/app.css" }}
+	AssetFuncName = "asset"
+	// AssetCacheControl is the Cache-Control header of the fingerprinted assets, their contents never change
+	AssetCacheControl = "public, max-age=31536000, immutable"
+	// assetHashLength the number of the hex characters of the fingerprint
+	assetHashLength = 10
+)
+
+// Assets serves the files of a http.FileSystem at fingerprinted urls, i.e /static/css/app.3f2a1b9c0d.css,
+// so they can be cached forever by the clients, a changed file has a different url.
+// The fingerprints are the hashes of the files (look NewAssets) or they are read from the manifest of a build tool (look NewAssetsFromManifest).
+//
+// Register it with the Party.StaticAssets, which registers the "asset" template func too.
+type Assets struct {
+	fs http.FileSystem
+	// prefix the request path of the assets, it's set by the StaticAssets
+	prefix string
+	// urls the fingerprinted names of the files by their names, i.e "css/app.css": "css/app.3f2a1b9c0d.css"
+	urls map[string]string
+	// files the names of the files by their fingerprinted names, the opposite of the urls
+	files map[string]string
+}
+
+// NewAssets hashes the files of the fs, which can be an http.Dir or any other file system of the utils,
+// the fingerprinted name of a file is its name with the hash before its extension, i.e css/app.3f2a1b9c0d.css.
+// Call it on startup, the files are not hashed again.
+func NewAssets(fs http.FileSystem) (*Assets, error) {
+	a := &Assets{fs: fs, urls: make(map[string]string), files: make(map[string]string)}
+	err := utils.WalkFS(fs, "/", func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		hash, err := hashFile(fs, name)
+		if err != nil {
+			return err
+		}
+		name = name[1:]
+		ext := path.Ext(name)
+		a.add(name, name[0:len(name)-len(ext)]+"."+hash+ext)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// NewAssetsFromManifest reads the fingerprinted names from a json manifest file of the fs,
+// which maps the names to the fingerprinted names, i.e {"css/app.css": "css/app.3f2a1b9c0d.css"}, as the build tools write it.
+// The fingerprinted files should exist in the fs.
+func NewAssetsFromManifest(fs http.FileSystem, manifest string) (*Assets, error) {
+	contents, err := utils.ReadFile(fs, manifest)
+	if err != nil {
+		return nil, err
+	}
+	urls := make(map[string]string)
+	if err = json.Unmarshal(contents, &urls); err != nil {
+		return nil, err
+	}
+
+	a := &Assets{fs: fs, urls: make(map[string]string), files: make(map[string]string)}
+	for name, fingerprinted := range urls {
+		fingerprinted = strings.TrimPrefix(fingerprinted, Slash)
+		// the file of the fingerprinted name is the fingerprinted file itself
+		a.add(strings.TrimPrefix(name, Slash), fingerprinted)
+		a.files[fingerprinted] = fingerprinted
+	}
+	return a, nil
+}
+
+func (a *Assets) add(name string, fingerprinted string) {
+	a.urls[name] = fingerprinted
+	a.files[fingerprinted] = name
+}
+
+// hashFile returns the first hex characters of the sha256 hash of a file
+func hashFile(fs http.FileSystem, name string) (string, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil))[0:assetHashLength], nil
+}
+
+// URL returns the fingerprinted url of an asset by its name, i.e "css/app.css" returns "/static/css/app.3f2a1b9c0d.css",
+// the names which are not known return their plain url.
+func (a *Assets) URL(name string) string {
+	name = strings.TrimPrefix(name, Slash)
+	if fingerprinted, ok := a.urls[name]; ok {
+		name = fingerprinted
+	}
+	return a.prefix + Slash + name
+}
+
+// Serve serves an asset by the "filepath" parameter of the route, the fingerprinted urls are served with the AssetCacheControl,
+// the plain urls are served as usual
+func (a *Assets) Serve(ctx *Context) {
+	name := path.Clean("/" + ctx.Param("filepath"))[1:]
+	file, fingerprinted := a.files[name]
+	if !fingerprinted {
+		file = name
+	}
+
+	f, info, err := openFile(a.fs, Slash+file)
+	if err != nil {
+		ctx.NotFound()
+		return
+	}
+	defer f.Close()
+
+	if fingerprinted {
+		ctx.Response.Header.Set("Cache-Control", AssetCacheControl)
+	}
+	ctx.HandleError(ctx.ServeContent(f, info.Name(), info.ModTime()))
+}
+
+// Funcs returns the template funcs of the assets, the "asset" func
+func (a *Assets) Funcs() template.FuncMap {
+	return template.FuncMap{AssetFuncName: a.URL}
+}
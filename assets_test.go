@@ -0,0 +1,131 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/kataras/iris/utils"
+)
+
+func testHash(contents string) string {
+	h := sha256.Sum256([]byte(contents))
+	return hex.EncodeToString(h[:])[0:assetHashLength]
+}
+
+func TestAssets(t *testing.T) {
+	assets, err := NewAssets(utils.NewMapFS(map[string][]byte{
+		"css/app.css": []byte("body { color: red; }"),
+		"js/app.js":   []byte("console.log(1)"),
+		"robots.txt":  []byte("User-agent: *"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cssURL := "/static/css/app." + testHash("body { color: red; }") + ".css"
+	s := newTestTemplatesIris(map[string][]byte{
+		"index.html": []byte(`<link href="{{ asset "css/app.css" }}"><script src="{{ asset "/js/app.js" }}"></script>`),
+	})
+	s.StaticAssets("/static", assets)
+	s.Get("/", func(ctx *Context) {
+		ctx.Render("index", nil)
+	})
+	testServe(s)
+
+	if url := assets.URL("css/app.css"); url != cssURL {
+		t.Fatalf("expected the url %s but got %s", cssURL, url)
+	}
+	// the names which are not known return their plain url
+	if url := assets.URL("/img/logo.png"); url != "/static/img/logo.png" {
+		t.Fatalf("expected the plain url but got %s", url)
+	}
+
+	expectResponse(t, testRequest(s, MethodGet, "/", ""), StatusOK,
+		`<link href="`+cssURL+`"><script src="/static/js/app.`+testHash("console.log(1)")+`.js"></script>`)
+
+	reqCtx := testRequest(s, MethodGet, cssURL, "")
+	expectResponse(t, reqCtx, StatusOK, "body { color: red; }")
+	if cacheControl := string(reqCtx.Response.Header.Peek("Cache-Control")); cacheControl != AssetCacheControl {
+		t.Fatalf("expected the Cache-Control %q but got %q", AssetCacheControl, cacheControl)
+	}
+
+	// the plain urls are served as usual
+	reqCtx = testRequest(s, MethodGet, "/static/robots.txt", "")
+	expectResponse(t, reqCtx, StatusOK, "User-agent: *")
+	if cacheControl := reqCtx.Response.Header.Peek("Cache-Control"); len(cacheControl) > 0 {
+		t.Fatalf("expected no Cache-Control for a plain url but got %q", cacheControl)
+	}
+
+	for _, missing := range []string{"/static/css/app.0000000000.css", "/static/missing.css", "/static/../iris.go"} {
+		if status := testRequest(s, MethodGet, missing, "").Response.StatusCode(); status != StatusNotFound {
+			t.Fatalf("%s: expected status code %d but got %d", missing, StatusNotFound, status)
+		}
+	}
+}
+
+func TestAssetsFromManifest(t *testing.T) {
+	fs := utils.NewMapFS(map[string][]byte{
+		"manifest.json":      []byte(`{"css/app.css": "/css/app.a1b2c3.css", "js/app.js": "js/app.d4e5f6.js"}`),
+		"css/app.a1b2c3.css": []byte("body {}"),
+		"js/app.d4e5f6.js":   []byte("run()"),
+		"img/logo.png":       []byte("png"),
+		"css/app.css":        []byte("body { old }"),
+	})
+	assets, err := NewAssetsFromManifest(fs, "manifest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := newTestIris()
+	s.Party("/admin").StaticAssets("/assets/", assets)
+	testServe(s)
+
+	if url := assets.URL("css/app.css"); url != "/admin/assets/css/app.a1b2c3.css" {
+		t.Fatalf("expected the url of the manifest but got %s", url)
+	}
+	if url := assets.URL("js/app.js"); url != "/admin/assets/js/app.d4e5f6.js" {
+		t.Fatalf("expected the url of the manifest but got %s", url)
+	}
+
+	reqCtx := testRequest(s, MethodGet, "/admin/assets/css/app.a1b2c3.css", "")
+	expectResponse(t, reqCtx, StatusOK, "body {}")
+	if cacheControl := string(reqCtx.Response.Header.Peek("Cache-Control")); cacheControl != AssetCacheControl {
+		t.Fatalf("expected the Cache-Control %q but got %q", AssetCacheControl, cacheControl)
+	}
+	expectResponse(t, testRequest(s, MethodGet, "/admin/assets/img/logo.png", ""), StatusOK, "png")
+
+	if _, err := NewAssetsFromManifest(fs, "missing.json"); err == nil {
+		t.Fatal("expected an error for a missing manifest")
+	}
+	if _, err := NewAssetsFromManifest(fs, "img/logo.png"); err == nil {
+		t.Fatal("expected an error for an invalid manifest")
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"sync"
+
+	"github.com/kataras/iris/logger"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// DefaultBatchMaxRequests is the default maximum number of sub-requests per batch request
+	DefaultBatchMaxRequests = 20
+	// DefaultBatchMaxConcurrency is the default number of sub-requests which are executed at the same time, if BatchConfig.Parallel is true
+	DefaultBatchMaxConcurrency = 4
+
+	// batchKey is the fasthttp's user value key which marks a sub-request of a batch request
+	batchKey = "iris.batch"
+)
+
+type (
+	// BatchConfig the options for the Party.Batch
+	BatchConfig struct {
+		// Parallel set to true to execute the sub-requests in parallel
+		// Default is false, the sub-requests are executed one after the other, in the order they were sent
+		Parallel bool
+		// MaxConcurrency the maximum number of sub-requests which are executed at the same time, used only if Parallel is true
+		// Default is 4
+		MaxConcurrency int
+		// MaxRequests the maximum number of sub-requests per batch request,
+		// if the client sends more than these then the server responds with status 413
+		// Default is 20
+		MaxRequests int
+	}
+
+	// BatchRequest is one sub-request of a batch request
+	// the path must start with a single slash, i.e "/users/42?tab=posts", the absolute urls are not accepted
+	// the body can be a JSON string or any other JSON value, which is sent as it is
+	BatchRequest struct {
+		Method  string            `json:"method"`
+		Path    string            `json:"path"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    json.RawMessage   `json:"body,omitempty"`
+	}
+
+	// BatchResponse is the response of one sub-request of a batch request
+	// the body is a JSON value if the sub-response is JSON (application/json or any +json, i.e application/problem+json), otherwise it's a JSON string
+	BatchResponse struct {
+		Status  int               `json:"status"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    json.RawMessage   `json:"body,omitempty"`
+	}
+)
+
+// DefaultBatchConfig returns the default BatchConfig for the Party.Batch
+func DefaultBatchConfig() BatchConfig {
+	return BatchConfig{Parallel: false, MaxConcurrency: DefaultBatchMaxConcurrency, MaxRequests: DefaultBatchMaxRequests}
+}
+
+// Batch registers a POST route which accepts a JSON array of sub-requests ({method, path, headers, body})
+// each one of them is executed through the router, in-process, with its own Context
+// and the route responds with a JSON array of their responses ({status, headers, body}), in the same order
+//
+// the sub-requests inherit the headers(cookies, authorization...) of the batch request
+// a sub-request with a path which doesn't start with a single slash is not executed, its response has status 400
+// a sub-request whose handler panics has status 500, the rest of the sub-requests are not affected
+// receives an optional BatchConfig, if empty then DefaultBatchConfig() is used
+func (p *GardenParty) Batch(path string, config ...BatchConfig) {
+	c := DefaultBatchConfig()
+	if len(config) > 0 {
+		c = config[0]
+		if c.MaxConcurrency <= 0 {
+			c.MaxConcurrency = DefaultBatchMaxConcurrency
+		}
+		if c.MaxRequests <= 0 {
+			c.MaxRequests = DefaultBatchMaxRequests
+		}
+	}
+
+	p.Post(path, func(ctx *Context) {
+		if ctx.RequestCtx.UserValue(batchKey) != nil {
+			// a batch inside a batch is not allowed
+			ctx.EmitError(StatusBadRequest)
+			return
+		}
+
+		var batch []BatchRequest
+		if err := json.Unmarshal(ctx.PostBody(), &batch); err != nil {
+			ctx.EmitError(StatusBadRequest)
+			return
+		}
+
+		if len(batch) > c.MaxRequests {
+			ctx.EmitError(StatusRequestEntityTooLarge)
+			return
+		}
+
+		// prepare the sub-requests here, the batch request should not be touched by the goroutines
+		responses := make([]BatchResponse, len(batch))
+		requests := make([]*fasthttp.Request, len(batch))
+		for i := range batch {
+			if !isBatchPath(batch[i].Path) {
+				responses[i] = BatchResponse{Status: StatusBadRequest}
+				continue
+			}
+			requests[i] = newBatchSubRequest(ctx, batch[i])
+		}
+
+		serve := ctx.station.router.ServeRequest
+		remoteAddr := ctx.RequestCtx.RemoteAddr()
+		log := ctx.station.Logger
+
+		if c.Parallel {
+			var wg sync.WaitGroup
+			sem := make(chan struct{}, c.MaxConcurrency)
+			for i := range requests {
+				if requests[i] == nil {
+					continue
+				}
+				wg.Add(1)
+				sem <- struct{}{}
+				go func(i int) {
+					responses[i] = serveBatchSubRequest(serve, requests[i], remoteAddr, log)
+					<-sem
+					wg.Done()
+				}(i)
+			}
+			wg.Wait()
+		} else {
+			for i := range requests {
+				if requests[i] != nil {
+					responses[i] = serveBatchSubRequest(serve, requests[i], remoteAddr, log)
+				}
+			}
+		}
+
+		ctx.JSON(StatusOK, responses)
+	})
+}
+
+// isBatchPath returns true if the path of a sub-request is a path of this server, it starts with a single slash,
+// the absolute urls ("http://host/path") and the network-path references ("//host/path") are not
+func isBatchPath(path string) bool {
+	return len(path) > 0 && path[0] == '/' && (len(path) == 1 || path[1] != '/' && path[1] != '\\')
+}
+
+// newBatchSubRequest creates the fasthttp.Request of a sub-request, with the headers of the batch request
+func newBatchSubRequest(ctx *Context, b BatchRequest) *fasthttp.Request {
+	req := new(fasthttp.Request)
+	ctx.Request.Header.CopyTo(&req.Header)
+	// the body of the sub-responses is embedded to the batch response, so it must not be compressed
+	req.Header.Del("Accept-Encoding")
+	req.Header.Del(ContentType)
+	req.Header.Del(ContentLength)
+
+	method := b.Method
+	if method == "" {
+		method = MethodGet
+	}
+	req.Header.SetMethod(method)
+	req.SetRequestURI(b.Path)
+
+	for k, v := range b.Headers {
+		req.Header.Set(k, v)
+	}
+
+	if body := bytes.TrimSpace(b.Body); len(body) > 0 && !bytes.Equal(body, []byte("null")) {
+		var s string
+		if body[0] == '"' && json.Unmarshal(body, &s) == nil {
+			req.SetBodyString(s)
+		} else {
+			req.SetBody(body)
+		}
+	}
+
+	return req
+}
+
+// serveBatchSubRequest executes a sub-request through the router and returns its response,
+// a panic of its handlers is recovered, logged, and its response has status 500
+func serveBatchSubRequest(serve func(*fasthttp.RequestCtx), req *fasthttp.Request, remoteAddr net.Addr, log *logger.Logger) (res BatchResponse) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Printf("Batch %s %s: panic: %v", req.Header.Method(), req.URI().RequestURI(), err)
+			res = BatchResponse{Status: StatusInternalServerError}
+		}
+	}()
+
+	reqCtx := new(fasthttp.RequestCtx)
+	reqCtx.Init(req, remoteAddr, nil)
+	reqCtx.SetUserValue(batchKey, true)
+	serve(reqCtx)
+
+	res = BatchResponse{Status: reqCtx.Response.StatusCode(), Headers: make(map[string]string)}
+	reqCtx.Response.Header.VisitAll(func(k, v []byte) {
+		key := string(k)
+		if prev, ok := res.Headers[key]; ok {
+			res.Headers[key] = prev + ", " + string(v)
+		} else {
+			res.Headers[key] = string(v)
+		}
+	})
+
+	body := reqCtx.Response.Body()
+	if len(body) > 0 {
+		var raw json.RawMessage
+		if isJSONContentType(reqCtx.Response.Header.ContentType()) && json.Unmarshal(body, &raw) == nil {
+			res.Body = append(json.RawMessage(nil), body...)
+		} else if encoded, err := json.Marshal(string(body)); err == nil {
+			res.Body = encoded
+		}
+	}
+
+	return res
+}
+
+// isJSONContentType returns true if the content type is the application/json or a +json one, i.e the application/problem+json
+func isJSONContentType(contentType []byte) bool {
+	if idx := bytes.IndexByte(contentType, ';'); idx >= 0 {
+		contentType = contentType[:idx]
+	}
+	contentType = bytes.ToLower(bytes.TrimSpace(contentType))
+	return bytes.Equal(contentType, []byte("application/json")) || bytes.HasSuffix(contentType, []byte("+json"))
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+)
+
+func newBatchTestIris(config ...BatchConfig) *Iris {
+	s := newTestIris()
+	s.Get("/users/:id", func(ctx *Context) {
+		ctx.JSON(StatusOK, map[string]string{"id": ctx.Param("id"), "auth": ctx.RequestHeader("Authorization")})
+	})
+	s.Post("/echo", func(ctx *Context) {
+		ctx.Text(StatusCreated, string(ctx.PostBody()))
+	})
+	s.Get("/problem", func(ctx *Context) {
+		ctx.SetStatusCode(StatusNotFound)
+		ctx.RequestCtx.SetContentType("application/problem+json; charset=UTF-8")
+		ctx.SetBodyString(`{"title":"Not Found","status":404}`)
+	})
+	s.Get("/panic", func(ctx *Context) {
+		panic("sub-request")
+	})
+	s.Batch("/batch", config...)
+	testServe(s)
+	return s
+}
+
+func decodeBatchResponses(t *testing.T, body []byte) []BatchResponse {
+	t.Helper()
+	var responses []BatchResponse
+	if err := json.Unmarshal(body, &responses); err != nil {
+		t.Fatalf("unexpected batch response %q: %v", body, err)
+	}
+	return responses
+}
+
+func TestBatch(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		s := newBatchTestIris(BatchConfig{Parallel: parallel})
+		reqCtx := testRequest(s, MethodPost, "/batch", `[
+			{"path": "/users/42"},
+			{"method": "POST", "path": "/echo", "body": "plain text"},
+			{"method": "POST", "path": "/echo", "body": {"a": 1}},
+			{"path": "/missing"},
+			{"path": "/problem"},
+			{"path": "/panic"},
+			{"path": "/users/7"}
+		]`, "Authorization", "Bearer token")
+
+		if status := reqCtx.Response.StatusCode(); status != StatusOK {
+			t.Fatalf("parallel=%v: expected status code %d but got %d", parallel, StatusOK, status)
+		}
+		responses := decodeBatchResponses(t, reqCtx.Response.Body())
+		if len(responses) != 7 {
+			t.Fatalf("parallel=%v: expected 7 responses but got %d", parallel, len(responses))
+		}
+
+		expected := []struct {
+			status int
+			body   string
+		}{
+			{StatusOK, `{"auth":"Bearer token","id":"42"}`},
+			{StatusCreated, `"plain text"`},
+			{StatusCreated, `"{\"a\": 1}"`},
+			{StatusNotFound, ""},
+			// the +json bodies are embedded as they are
+			{StatusNotFound, `{"title":"Not Found","status":404}`},
+			// a panic is the 500 of its sub-request only
+			{StatusInternalServerError, ""},
+			{StatusOK, `{"auth":"Bearer token","id":"7"}`},
+		}
+		for i, e := range expected {
+			if responses[i].Status != e.status {
+				t.Fatalf("parallel=%v: response %d: expected status code %d but got %d", parallel, i, e.status, responses[i].Status)
+			}
+			if e.body != "" && string(responses[i].Body) != e.body {
+				t.Fatalf("parallel=%v: response %d: expected body %s but got %s", parallel, i, e.body, responses[i].Body)
+			}
+		}
+	}
+}
+
+func TestBatchParallelConcurrency(t *testing.T) {
+	s := newTestIris()
+	var running, max int32
+	s.Get("/slow", func(ctx *Context) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		atomic.AddInt32(&running, -1)
+		ctx.Text(StatusOK, "ok")
+	})
+	s.Batch("/batch", BatchConfig{Parallel: true, MaxConcurrency: 2})
+	testServe(s)
+
+	reqCtx := testRequest(s, MethodPost, "/batch", `[{"path":"/slow"},{"path":"/slow"},{"path":"/slow"},{"path":"/slow"},{"path":"/slow"}]`)
+	for i, res := range decodeBatchResponses(t, reqCtx.Response.Body()) {
+		if res.Status != StatusOK {
+			t.Fatalf("response %d: expected status code %d but got %d", i, StatusOK, res.Status)
+		}
+	}
+	if m := atomic.LoadInt32(&max); m > 2 {
+		t.Fatalf("expected at most 2 sub-requests at the same time but got %d", m)
+	}
+}
+
+func TestBatchInvalidPath(t *testing.T) {
+	s := newBatchTestIris()
+	reqCtx := testRequest(s, MethodPost, "/batch", `[
+		{"path": "http://example.com/users/1"},
+		{"path": "//example.com/users/1"},
+		{"path": "/\\example.com/users/1"},
+		{"path": "users/1"},
+		{"path": ""},
+		{"path": "/users/1"}
+	]`)
+
+	responses := decodeBatchResponses(t, reqCtx.Response.Body())
+	if len(responses) != 6 {
+		t.Fatalf("expected 6 responses but got %d", len(responses))
+	}
+	for i := 0; i < 5; i++ {
+		if responses[i].Status != StatusBadRequest {
+			t.Fatalf("response %d: expected status code %d but got %d", i, StatusBadRequest, responses[i].Status)
+		}
+	}
+	if responses[5].Status != StatusOK {
+		t.Fatalf("expected status code %d for a valid path but got %d", StatusOK, responses[5].Status)
+	}
+}
+
+func TestBatchRejected(t *testing.T) {
+	s := newBatchTestIris(BatchConfig{MaxRequests: 2})
+
+	reqCtx := testRequest(s, MethodPost, "/batch", "not json")
+	if status := reqCtx.Response.StatusCode(); status != StatusBadRequest {
+		t.Fatalf("expected status code %d for an invalid body but got %d", StatusBadRequest, status)
+	}
+
+	reqCtx = testRequest(s, MethodPost, "/batch", `[{"path":"/users/1"},{"path":"/users/2"},{"path":"/users/3"}]`)
+	if status := reqCtx.Response.StatusCode(); status != StatusRequestEntityTooLarge {
+		t.Fatalf("expected status code %d for too many sub-requests but got %d", StatusRequestEntityTooLarge, status)
+	}
+
+	// a batch inside a batch
+	reqCtx = testRequest(s, MethodPost, "/batch", `[{"method":"POST","path":"/batch","body":"[]"}]`)
+	responses := decodeBatchResponses(t, reqCtx.Response.Body())
+	if len(responses) != 1 || responses[0].Status != StatusBadRequest {
+		t.Fatalf("expected a nested batch to be rejected with status code %d but got %s", StatusBadRequest, reqCtx.Response.Body())
+	}
+}
@@ -31,8 +31,10 @@ package iris
 import (
 	"reflect"
 	"runtime"
+	"strings"
 	"time"
 
+	"github.com/kataras/iris/render"
 	"github.com/valyala/fasthttp"
 	"golang.org/x/net/context"
 )
@@ -55,12 +57,27 @@ const (
 	LastModified = "Last-Modified"
 	// IfModifiedSince "If-Modified-Since"
 	IfModifiedSince = "If-Modified-Since"
+	// Range "Range"
+	Range = "Range"
+	// ContentRange "Content-Range"
+	ContentRange = "Content-Range"
+	// AcceptRanges "Accept-Ranges"
+	AcceptRanges = "Accept-Ranges"
 	// ContentDisposition "Content-Disposition"
 	ContentDisposition = "Content-Disposition"
 
 	// TimeFormat default time format for any kind of datetime parsing
 	TimeFormat = "Mon, 02 Jan 2006 15:04:05 GMT"
 
+	// doneExecutedKey is the fasthttp's user value key which is set when the done handlers of a request are executed
+	doneExecutedKey = "iris.done.executed"
+
+	// forwardValuesKey is the fasthttp's user value key which keeps the request storage of the forwarding Context, look Exec
+	forwardValuesKey = "iris.forward.values"
+
+	// forwardDepthKey is the fasthttp's user value key which keeps the depth of the .Forward/.Exec calls of a request
+	forwardDepthKey = "iris.forward.depth"
+
 	// stopExecutionPosition used inside the Context, is the number which shows us that the context's middleware manualy stop the execution
 	stopExecutionPosition = 255
 )
@@ -84,6 +101,9 @@ type (
 		Clone() *Context
 		Do()
 		Next()
+		Forward(string) error
+		Exec(string, string) error
+		OnEnd(func(*Context))
 		StopExecution()
 		IsStopped() bool
 		GetHandlerName() string
@@ -102,6 +122,14 @@ type (
 		// these values are reseting on each request, are useful only between middleware,
 		// use iris/sessions for cookie/filesystem storage
 		values map[interface{}]interface{}
+		// done the route's done handlers, look Party.Done
+		done Middleware
+		// onEnd the funcs registed via .OnEnd for this request only
+		onEnd []func(*Context)
+		// streamWrappers the wrappers registed via .WrapStream for this request only
+		streamWrappers []StreamWrapper
+		// render the render of the route's party, if it has its own, look Party.SetRenderConfig
+		render *render.Render
 	}
 )
 
@@ -128,15 +156,12 @@ func (ctx *Context) Err() error {
 }
 
 // Value returns the value associated with key or nil if none.
+// the values are the same as the ctx.Get/ctx.Set and the ContextKey's values
 func (ctx *Context) Value(key interface{}) interface{} {
 	if key == 0 {
 		return ctx.Request
 	}
-	if keyAsString, ok := key.(string); ok {
-		val := ctx.GetString(keyAsString)
-		return val
-	}
-	return nil
+	return ctx.Get(key)
 }
 
 // Reset resets the Context with a given domain.Response and domain.Request
@@ -145,7 +170,27 @@ func (ctx *Context) Value(key interface{}) interface{} {
 func (ctx *Context) Reset(reqCtx *fasthttp.RequestCtx) {
 	ctx.Params = ctx.Params[0:0]
 	ctx.middleware = nil
+	ctx.done = nil
+	// the funcs are released, the backing array is kept for the next request
+	for i := range ctx.onEnd {
+		ctx.onEnd[i] = nil
+	}
+	ctx.onEnd = ctx.onEnd[0:0]
+	for i := range ctx.streamWrappers {
+		ctx.streamWrappers[i] = nil
+	}
+	ctx.streamWrappers = ctx.streamWrappers[0:0]
+	ctx.render = nil
+	ctx.clearValues()
 	ctx.RequestCtx = reqCtx
+	if reqCtx != nil {
+		// a forwarded request keeps the values of the forwarding Context, look Exec
+		if values, ok := reqCtx.UserValue(forwardValuesKey).(map[interface{}]interface{}); ok {
+			for k, v := range values {
+				ctx.Set(k, v)
+			}
+		}
+	}
 }
 
 // Clone use that method if you want to use the context inside a goroutine
@@ -163,6 +208,18 @@ func (ctx *Context) Clone() *Context {
 	cpM := make(Middleware, len(m))
 	copy(cpM, m)
 	cloneContext.middleware = cpM
+	//the clone is not served by the router, so it has nothing to do with the done handlers
+	cloneContext.done = nil
+	cloneContext.onEnd = nil
+	cloneContext.streamWrappers = nil
+	//copy values, the original map is cleared when the Context goes back to the pool
+	if ctx.values != nil {
+		cpV := make(map[interface{}]interface{}, len(ctx.values))
+		for k, v := range ctx.values {
+			cpV[k] = v
+		}
+		cloneContext.values = cpV
+	}
 	return &cloneContext
 }
 
@@ -174,6 +231,9 @@ func (ctx *Context) Do() {
 
 // Next calls all the next handler from the middleware stack, it used inside a middleware
 func (ctx *Context) Next() {
+	if ctx.IsStopped() {
+		return
+	}
 	//set position to the next
 	ctx.pos++
 	midLen := uint8(len(ctx.middleware)) // max 255 handlers, we don't except more than these logically ...
@@ -184,6 +244,76 @@ func (ctx *Context) Next() {
 
 }
 
+// Forward same as Exec but keeps the request's method
+func (ctx *Context) Forward(path string) error {
+	return ctx.Exec(ctx.MethodString(), path)
+}
+
+// Exec re-dispatches the current request, in-process, to the route which is registed for the given method and path
+// it is like a redirect but without the round-trip to the client, the response is the same and
+// the path parameters are parsed again for the new route.
+// The values of the request storage (ctx.Set and the ContextKeys) are copied to the Context of the new route,
+// the values which the new route sets are not copied back
+// the path can contain a query string, if not then the request's query string is kept
+//
+// the execution of the current handlers is stopped after the Exec
+// returns an error if the depth of the forwards is bigger than the IrisConfig.MaxForwardDepth (propably a loop)
+func (ctx *Context) Exec(method string, path string) error {
+	depth, _ := ctx.RequestCtx.UserValue(forwardDepthKey).(int)
+	if depth >= ctx.station.Config.MaxForwardDepth {
+		return ErrForwardDepth.Format(path, ctx.station.Config.MaxForwardDepth)
+	}
+
+	// keep a copy of the method and the uri in order to restore them after the execution
+	originalMethod := append([]byte(nil), ctx.Method()...)
+	originalURI := append([]byte(nil), ctx.Request.RequestURI()...)
+
+	ctx.Request.Header.SetMethod(method)
+	if strings.IndexByte(path, '?') != -1 {
+		ctx.Request.SetRequestURI(path)
+	} else {
+		ctx.Request.URI().SetPath(path)
+	}
+
+	// the Context of the new route copies the values on its Reset
+	previousValues := ctx.RequestCtx.UserValue(forwardValuesKey)
+	ctx.RequestCtx.SetUserValue(forwardValuesKey, ctx.values)
+	ctx.RequestCtx.SetUserValue(forwardDepthKey, depth+1)
+	ctx.station.router.ServeRequest(ctx.RequestCtx)
+	ctx.RequestCtx.SetUserValue(forwardDepthKey, depth)
+	ctx.RequestCtx.SetUserValue(forwardValuesKey, previousValues)
+
+	ctx.Request.Header.SetMethodBytes(originalMethod)
+	ctx.Request.SetRequestURIBytes(originalURI)
+	ctx.StopExecution()
+	return nil
+}
+
+// OnEnd registers a func which will be executed after the response is produced, for this request only
+// these funcs are executed after the route's done handlers (look Party.Done), even if the handlers stopped the execution
+func (ctx *Context) OnEnd(cb func(*Context)) {
+	ctx.onEnd = append(ctx.onEnd, cb)
+}
+
+// end executes the done handlers and the OnEnd funcs, it's called by the router after the main handlers
+// the done handlers are executed once per request, the ones of the route which served it last,
+// so a forwarded request (look Forward) doesn't execute the done handlers of the forwarding route again
+func (ctx *Context) end() {
+	if len(ctx.done) > 0 && ctx.RequestCtx.UserValue(doneExecutedKey) == nil {
+		ctx.RequestCtx.SetUserValue(doneExecutedKey, true)
+		ctx.middleware = ctx.done
+		for i := range ctx.done {
+			// the done handlers are always executed one after the other, so .Next does nothing inside them
+			ctx.pos = stopExecutionPosition
+			ctx.done[i].Serve(ctx)
+		}
+	}
+
+	for i := range ctx.onEnd {
+		ctx.onEnd[i](ctx)
+	}
+}
+
 // StopExecution just sets the .pos to 255 in order to  not move to the next middlewares(if any)
 func (ctx *Context) StopExecution() {
 	ctx.pos = stopExecutionPosition
@@ -36,7 +36,10 @@ import (
 	"path"
 	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/kataras/iris/render"
 	"github.com/kataras/iris/utils"
+	"github.com/valyala/fasthttp"
 )
 
 type (
@@ -50,20 +53,32 @@ type (
 		HTML(status int, name string, binding interface{}, htmlOpt ...HTMLOptions) error
 		// Render same as .HTML but with status to iris.StatusOK (200)
 		Render(name string, binding interface{}, htmlOpt ...HTMLOptions) error
+		// SetTemplateFunc sets a template func for this request only, look render.DeclareRequestFunc
+		SetTemplateFunc(name string, fn interface{})
 		// JSON marshals the given interface object and writes the JSON response.
 		JSON(status int, v interface{}) error
 		// JSONP marshals the given interface object and writes the JSON response.
 		JSONP(status int, callback string, v interface{}) error
+		JSONStream(status int, ch <-chan interface{}, options ...JSONStreamOptions) error
+		JSONStreamFunc(status int, next func() (interface{}, bool), options ...JSONStreamOptions) error
 		// Text writes out a string as plain text.
 		Text(status int, v string) error
 		// XML marshals the given interface object and writes the XML response.
 		XML(status int, v interface{}) error
+		Markdown(status int, markdown string) error
+		CSV(status int, v interface{}) error
+		YAML(status int, v interface{}) error
+		MsgPack(status int, v interface{}) error
+		Protobuf(status int, v proto.Message) error
+		// Problem writes a problem details object (RFC 7807), as json or xml by the Accept header
+		Problem(status int, problem Problem) error
 
 		ExecuteTemplate(*template.Template, interface{}) error
 		ServeContent(io.ReadSeeker, string, time.Time) error
 		ServeFile(string) error
 		SendFile(filename string, destinationName string) error
 		Stream(func(*bufio.Writer))
+		WrapStream(StreamWrapper)
 	}
 )
 
@@ -80,16 +95,24 @@ func (ctx *Context) WriteHTML(httpStatus int, htmlContents string) {
 	ctx.RequestCtx.WriteString(htmlContents)
 }
 
+// renderer returns the render of the party which the route belongs to, or the station's render
+func (ctx *Context) renderer() *render.Render {
+	if ctx.render != nil {
+		return ctx.render
+	}
+	return ctx.station.render
+}
+
 // Data writes out the raw bytes as binary data.
 func (ctx *Context) Data(status int, v []byte) error {
-	return ctx.station.render.Data(ctx.RequestCtx, status, v)
+	return ctx.renderer().Data(ctx.RequestCtx, status, v)
 }
 
 // HTML builds up the response from the specified template and bindings.
 func (ctx *Context) HTML(status int, name string, binding interface{}, htmlOpt ...HTMLOptions) error {
 	opt := parseHTMLOptions(htmlOpt...)
 
-	return ctx.station.render.HTML(ctx.RequestCtx, status, name, binding, opt...)
+	return ctx.renderer().HTML(ctx.RequestCtx, status, name, binding, opt...)
 }
 
 // Render same as .HTML but with status to iris.StatusOK (200)
@@ -97,24 +120,85 @@ func (ctx *Context) Render(name string, binding interface{}, htmlOpt ...HTMLOpti
 	return ctx.HTML(StatusOK, name, binding, htmlOpt...)
 }
 
+// SetTemplateFunc sets a template func for this request only, it is merged with the RenderConfig.Funcs when a template is rendered,
+// without compiling the templates again. Useful for middleware, i.e a translator of the request's language or the host's urls.
+//
+// The html/template and the handlebars templates should know the func's name when they are compiled, so it should have a default,
+// with the same type, in the RenderConfig.Funcs or declared by render.DeclareRequestFunc.
+func (ctx *Context) SetTemplateFunc(name string, fn interface{}) {
+	render.SetRequestFunc(ctx.RequestCtx, name, fn)
+}
+
 // JSON marshals the given interface object and writes the JSON response.
 func (ctx *Context) JSON(status int, v interface{}) error {
-	return ctx.station.render.JSON(ctx.RequestCtx, status, v)
+	return ctx.renderer().JSON(ctx.RequestCtx, status, v)
 }
 
 // JSONP marshals the given interface object and writes the JSON response.
 func (ctx *Context) JSONP(status int, callback string, v interface{}) error {
-	return ctx.station.render.JSONP(ctx.RequestCtx, status, callback, v)
+	return ctx.renderer().JSONP(ctx.RequestCtx, status, callback, v)
+}
+
+// JSONStream streams the values of the channel as newline-delimited JSON (or as a JSON array if the JSONStreamOptions.Array is true)
+// the values are sent to the client every JSONStreamOptions.FlushEvery values and when the channel has no value ready.
+// When the client disconnects the encoding stops and the rest values of the channel are dropped, the streaming ends when the channel is closed
+func (ctx *Context) JSONStream(status int, ch <-chan interface{}, options ...JSONStreamOptions) error {
+	return ctx.renderer().JSONStream(ctx.RequestCtx, status, ch, parseJSONStreamOptions(options...)...)
+}
+
+// JSONStreamFunc same as JSONStream but the values are taken from the next func, until it returns false
+// next is not called again after the client is disconnected, so it's the way to stream large query results, i.e with a database cursor
+func (ctx *Context) JSONStreamFunc(status int, next func() (interface{}, bool), options ...JSONStreamOptions) error {
+	return ctx.renderer().JSONStreamFunc(ctx.RequestCtx, status, next, parseJSONStreamOptions(options...)...)
 }
 
 // Text writes out a string as plain text.
 func (ctx *Context) Text(status int, v string) error {
-	return ctx.station.render.Text(ctx.RequestCtx, status, v)
+	return ctx.renderer().Text(ctx.RequestCtx, status, v)
 }
 
 // XML marshals the given interface object and writes the XML response.
 func (ctx *Context) XML(status int, v interface{}) error {
-	return ctx.station.render.XML(ctx.RequestCtx, status, v)
+	return ctx.renderer().XML(ctx.RequestCtx, status, v)
+}
+
+// CSV writes the rows of a slice, array or receive channel of structs as csv response
+// the header row is built from the `csv:"name"` tags of the fields, a [][]string is written as it is.
+// The rows are streamed to the client, so large exports are not buffered in memory
+func (ctx *Context) CSV(status int, v interface{}) error {
+	return ctx.renderer().CSV(ctx.RequestCtx, status, v)
+}
+
+// YAML marshals the given interface object and writes the YAML response.
+func (ctx *Context) YAML(status int, v interface{}) error {
+	return ctx.renderer().YAML(ctx.RequestCtx, status, v)
+}
+
+// MsgPack marshals the given interface object and writes the MessagePack response.
+func (ctx *Context) MsgPack(status int, v interface{}) error {
+	return ctx.renderer().MsgPack(ctx.RequestCtx, status, v)
+}
+
+// Protobuf marshals the given proto.Message and writes the Protobuf response.
+func (ctx *Context) Protobuf(status int, v proto.Message) error {
+	return ctx.renderer().Protobuf(ctx.RequestCtx, status, v)
+}
+
+// Problem writes the problem details object (RFC 7807) as application/problem+json response,
+// or as application/problem+xml if the client prefers xml by its Accept header.
+// The status is the problem's Status and, if the problem has no Type and no Title, the Title is the status text.
+func (ctx *Context) Problem(status int, problem Problem) error {
+	problem.Status = status
+	if problem.Type == "" && problem.Title == "" {
+		problem.Title = StatusText(status)
+	}
+	return ctx.renderer().Problem(ctx.RequestCtx, status, problem)
+}
+
+// Markdown converts the markdown source to html and writes it as html response
+// the html is sanitized if the RenderConfig.MarkdownSanitize is true
+func (ctx *Context) Markdown(status int, markdown string) error {
+	return ctx.renderer().Markdown(ctx.RequestCtx, status, []byte(markdown))
 }
 
 // ExecuteTemplate executes a simple html template, you can use that if you already have the cached templates
@@ -184,7 +268,43 @@ func (ctx *Context) SendFile(filename string, destinationName string) error {
 	return nil
 }
 
+// StreamWrapper receives the writer func of a .Stream call and returns the one which will be used instead,
+// look Context.WrapStream
+type StreamWrapper func(cb func(writer *bufio.Writer)) func(writer *bufio.Writer)
+
 // Stream use that to do data steaming
 func (ctx *Context) Stream(cb func(writer *bufio.Writer)) {
-	ctx.RequestCtx.SetBodyStreamWriter(cb)
+	ctx.SetBodyStreamWriter(cb)
+}
+
+// SetBodyStreamWriter same as the fasthttp's but the writer passes through the wrappers of the .WrapStream
+func (ctx *Context) SetBodyStreamWriter(sw fasthttp.StreamWriter) {
+	ctx.RequestCtx.SetBodyStreamWriter(ctx.wrapStream(sw))
+}
+
+// SetBodyStream same as the fasthttp's but, if there are wrappers registed by the .WrapStream, the body is streamed through them
+// and the bodySize is ignored
+func (ctx *Context) SetBodyStream(bodyStream io.Reader, bodySize int) {
+	if len(ctx.streamWrappers) == 0 {
+		ctx.RequestCtx.SetBodyStream(bodyStream, bodySize)
+		return
+	}
+	render.SetBodyStream(ctx.RequestCtx, bodyStream)
+}
+
+// WrapStream registers a wrapper for the body stream writers of this request: the .Stream, the .SetBodyStream(Writer)
+// and the streaming responses of the render (JSONStream, CSV and the streaming HTML),
+// it's useful for middleware which should transform the streaming responses, i.e compression
+func (ctx *Context) WrapStream(wrapper StreamWrapper) {
+	ctx.streamWrappers = append(ctx.streamWrappers, wrapper)
+	render.SetStreamWrapper(ctx.RequestCtx, ctx.wrapStream)
+}
+
+// wrapStream passes the writer func through the wrappers of the .WrapStream
+func (ctx *Context) wrapStream(cb func(writer *bufio.Writer)) func(writer *bufio.Writer) {
+	// the first registed wrapper is the outer one, it's the closest to the client
+	for i := len(ctx.streamWrappers) - 1; i >= 0; i-- {
+		cb = ctx.streamWrappers[i](cb)
+	}
+	return cb
 }
@@ -0,0 +1,87 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kataras/iris/render"
+)
+
+func TestStreamingHTMLConcurrent(t *testing.T) {
+	render.DeclareRequestFunc("requestID", func() string { return "" })
+	s := newTestTemplatesIris(map[string][]byte{
+		"list.html": []byte(`{{ $id := requestID }}{{ range . }}{{ $id }}:{{ . }};{{ end }}`),
+	})
+	s.Config.Render.StreamingHTML = true
+	s.Config.Render.StreamingHTMLHeadSize = 32
+
+	s.Get("/list/:id", func(ctx *Context) {
+		id := ctx.Param("id")
+		ctx.SetTemplateFunc("requestID", func() string { return id })
+		n, _ := strconv.Atoi(id)
+		items := make([]int, 200)
+		for i := range items {
+			items[i] = n
+		}
+		ctx.Render("list", items)
+	})
+	testServe(s)
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := strconv.Itoa(i)
+			reqCtx := testRequest(s, MethodGet, "/list/"+id, "")
+			if !reqCtx.Response.IsBodyStream() {
+				errs <- id + ": expected the page to be streamed"
+				return
+			}
+			var body bytes.Buffer
+			if err := reqCtx.Response.BodyWriteTo(&body); err != nil {
+				errs <- id + ": " + err.Error()
+				return
+			}
+			if expected := strings.Repeat(id+":"+id+";", 200); body.String() != expected {
+				errs <- id + ": unexpected body " + body.String()
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
@@ -28,6 +28,7 @@
 package iris
 
 import (
+	"crypto/x509"
 	"net"
 	"strconv"
 	"strings"
@@ -48,6 +49,7 @@ type (
 		PathString() string
 		RequestIP() string
 		RemoteAddr() string
+		ClientCertificate() *x509.Certificate
 		RequestHeader(k string) string
 		PostFormValue(string) string
 	}
@@ -126,6 +128,16 @@ func (ctx *Context) RemoteAddr() string {
 
 }
 
+// ClientCertificate returns the verified certificate of the client, when the server requires or verifies the client certificates (mutual tls),
+// look server.TLSConfig's ClientCAFile. It returns nil if the connection is not tls or the client didn't send a verified certificate
+func (ctx *Context) ClientCertificate() *x509.Certificate {
+	state := ctx.RequestCtx.TLSConnectionState()
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
+
 // RequestHeader returns the request header's value
 // accepts one parameter, the key of the header (string)
 // returns string
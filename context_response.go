@@ -39,6 +39,7 @@ type (
 		NotFound()
 		Panic()
 		EmitError(int)
+		HandleError(error)
 		//
 	}
 )
@@ -93,3 +94,11 @@ func (ctx *Context) Panic() {
 func (ctx *Context) EmitError(statusCode int) {
 	ctx.station.EmitError(statusCode, ctx)
 }
+
+// HandleError passes an error to the central error handler, look iris.OnHandlerError
+func (ctx *Context) HandleError(err error) {
+	if err == nil {
+		return
+	}
+	ctx.station.HandleError(err, ctx)
+}
@@ -42,6 +42,8 @@ type (
 		GetString(interface{}) string
 		GetInt(interface{}) int
 		Set(interface{}, interface{})
+		Delete(interface{})
+		VisitValues(func(interface{}, interface{}))
 		SetCookie(*fasthttp.Cookie)
 		SetCookieKV(string, string)
 		RemoveCookie(string)
@@ -51,8 +53,77 @@ type (
 		SetFlash(string, string)
 		SetFlashBytes(string, []byte)
 	}
+
+	// ContextKey is a unique key for the Context's request storage
+	// two keys created with the same name are still different keys, so they never collide
+	// with each other or with the string keys which are used by the middleware
+	//
+	// Usage:
+	// var userKey = iris.NewContextKey("user")
+	// userKey.Set(ctx, user) // inside a middleware
+	// user := userKey.MustGet(ctx).(*User) // inside the main handler
+	ContextKey struct {
+		name string
+	}
 )
 
+// NewContextKey creates and returns a new ContextKey
+// the name is used only for debugging and error messages
+func NewContextKey(name string) *ContextKey {
+	return &ContextKey{name: name}
+}
+
+// String returns the name of the key
+func (k *ContextKey) String() string {
+	return "iris.ContextKey(" + k.name + ")"
+}
+
+// Set sets the value of this key to the Context's request storage
+func (k *ContextKey) Set(ctx *Context, value interface{}) {
+	ctx.Set(k, value)
+}
+
+// Get returns the value of this key and true if the key exists in the Context's request storage
+func (k *ContextKey) Get(ctx *Context) (value interface{}, ok bool) {
+	if ctx.values == nil {
+		return
+	}
+	value, ok = ctx.values[k]
+	return
+}
+
+// MustGet same as Get but it panics if the key doesn't exists in the Context's request storage
+func (k *ContextKey) MustGet(ctx *Context) interface{} {
+	value, ok := k.Get(ctx)
+	if !ok {
+		panic(ErrContextKeyMissing.Format(k.name))
+	}
+	return value
+}
+
+// GetString same as Get but returns the value as string
+// returns an empty string if the key doesn't exists or the value is not a string
+func (k *ContextKey) GetString(ctx *Context) (value string) {
+	if v, ok := k.Get(ctx); ok {
+		value, _ = v.(string)
+	}
+	return
+}
+
+// GetInt same as Get but returns the value as int
+// returns 0 if the key doesn't exists or the value is not an int
+func (k *ContextKey) GetInt(ctx *Context) (value int) {
+	if v, ok := k.Get(ctx); ok {
+		value, _ = v.(int)
+	}
+	return
+}
+
+// Delete removes this key from the Context's request storage
+func (k *ContextKey) Delete(ctx *Context) {
+	ctx.Delete(k)
+}
+
 // Get returns a value from a key
 // if doesn't exists returns nil
 func (ctx *Context) Get(key interface{}) interface{} {
@@ -66,28 +137,24 @@ func (ctx *Context) Get(key interface{}) interface{} {
 // GetFmt returns a value which has this format: func(format string, args ...interface{}) string
 // if doesn't exists returns nil
 func (ctx *Context) GetFmt(key interface{}) func(format string, args ...interface{}) string {
-	if ctx.values == nil {
-		return nil
+	if v, ok := ctx.Get(key).(func(format string, args ...interface{}) string); ok {
+		return v
 	}
 
-	return ctx.values[key].(func(format string, args ...interface{}) string)
+	return nil
 }
 
 // GetString same as Get but returns the value as string
+// returns an empty string if the key doesn't exists or the value is not a string
 func (ctx *Context) GetString(key interface{}) (value string) {
-	if v := ctx.Get(key); v != nil {
-		value = v.(string)
-	}
-
+	value, _ = ctx.Get(key).(string)
 	return
 }
 
 // GetInt same as Get but returns the value as int
+// returns 0 if the key doesn't exists or the value is not an int
 func (ctx *Context) GetInt(key interface{}) (value int) {
-	if v := ctx.Get(key); v != nil {
-		value = v.(int)
-	}
-
+	value, _ = ctx.Get(key).(int)
 	return
 }
 
@@ -100,6 +167,27 @@ func (ctx *Context) Set(key interface{}, value interface{}) {
 	ctx.values[key] = value
 }
 
+// Delete removes a key from the values map
+func (ctx *Context) Delete(key interface{}) {
+	if ctx.values != nil {
+		delete(ctx.values, key)
+	}
+}
+
+// VisitValues calls the visitor for each of the key-value pairs of the values map
+func (ctx *Context) VisitValues(visitor func(key interface{}, value interface{})) {
+	for k, v := range ctx.values {
+		visitor(k, v)
+	}
+}
+
+// clearValues empties the values map, the map itself is kept in order to be re-used by the next request
+func (ctx *Context) clearValues() {
+	for k := range ctx.values {
+		delete(ctx.values, k)
+	}
+}
+
 // GetCookie returns cookie's value by it's name
 // returns empty string if nothing was found
 func (ctx *Context) GetCookie(name string) (val string) {
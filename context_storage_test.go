@@ -0,0 +1,85 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import "testing"
+
+func TestContextValuesClearedBetweenRequests(t *testing.T) {
+	s := newTestIris()
+	s.Get("/set", func(ctx *Context) {
+		ctx.Set("user", "bob")
+		ctx.Write("%v", ctx.Get("user"))
+	})
+	s.Get("/get", func(ctx *Context) {
+		ctx.Write("%v", ctx.Get("user"))
+	})
+	testServe(s)
+
+	// the contexts are pooled, a value of a previous request must not be visible
+	for i := 0; i < 10; i++ {
+		expectResponse(t, testRequest(s, "GET", "/set", ""), 200, "bob")
+		expectResponse(t, testRequest(s, "GET", "/get", ""), 200, "<nil>")
+	}
+}
+
+func TestContextKey(t *testing.T) {
+	userKey := NewContextKey("user")
+	otherKey := NewContextKey("user")
+
+	s := newTestIris()
+	s.Get("/", func(ctx *Context) {
+		userKey.Set(ctx, "bob")
+		ctx.Set("user", "string key")
+		if _, ok := otherKey.Get(ctx); ok {
+			t.Fatalf("two keys with the same name must not collide")
+		}
+		if got := userKey.GetString(ctx); got != "bob" {
+			t.Fatalf("expected %q but got %q", "bob", got)
+		}
+		if got := ctx.Value(userKey); got != "bob" {
+			t.Fatalf("expected the ctx.Value to return the key's value but got %v", got)
+		}
+
+		clone := ctx.Clone()
+		userKey.Delete(ctx)
+		if got := userKey.GetString(clone); got != "bob" {
+			t.Fatalf("expected the clone to keep its own copy of the values but got %q", got)
+		}
+
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("expected MustGet of a missing key to panic")
+			}
+			ctx.Write("ok")
+		}()
+		userKey.MustGet(ctx)
+	})
+	testServe(s)
+
+	expectResponse(t, testRequest(s, "GET", "/", ""), 200, "ok")
+}
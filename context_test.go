@@ -0,0 +1,137 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDoneAndOnEnd(t *testing.T) {
+	var executed []string
+	record := func(name string) HandlerFunc {
+		return func(ctx *Context) {
+			executed = append(executed, name+":"+ctx.PathString())
+		}
+	}
+
+	s := newTestIris()
+	s.DoneFunc(record("done"))
+	s.Get("/stop", func(ctx *Context) {
+		ctx.OnEnd(record("onend"))
+		ctx.Write("stop")
+		ctx.StopExecution()
+	})
+	s.Get("/old", func(ctx *Context) {
+		ctx.OnEnd(record("onend"))
+		ctx.Forward("/new")
+	})
+	s.Get("/new", func(ctx *Context) {
+		ctx.Write("new")
+	})
+	s.Get("/dir", func(ctx *Context) {})
+	testServe(s)
+
+	tests := []struct {
+		path     string
+		status   int
+		executed string
+	}{
+		// the done handlers and the OnEnd funcs are executed even if the execution is stopped
+		{"/stop", 200, "done:/stop onend:/stop"},
+		// a forwarded request executes the done handlers once, the ones of the route which served it
+		{"/old", 200, "done:/new onend:/old"},
+		// the done handlers of the station are executed for the not found requests
+		{"/missing", 404, "done:/missing"},
+		// and for the path correction's redirects
+		{"/dir/", 301, "done:/dir"},
+	}
+
+	for _, tt := range tests {
+		executed = nil
+		reqCtx := testRequest(s, "GET", tt.path, "")
+		if got := reqCtx.Response.StatusCode(); got != tt.status {
+			t.Fatalf("%s: expected status code %d but got %d", tt.path, tt.status, got)
+		}
+		if got := strings.Join(executed, " "); got != tt.executed {
+			t.Fatalf("%s: expected the %q to be executed but got %q", tt.path, tt.executed, got)
+		}
+	}
+}
+
+func TestOnEndReleasedOnReset(t *testing.T) {
+	ctx := &Context{}
+	ctx.OnEnd(func(*Context) {})
+	ctx.OnEnd(func(*Context) {})
+	onEnd := ctx.onEnd
+
+	ctx.Reset(nil)
+	if len(ctx.onEnd) != 0 {
+		t.Fatalf("expected no OnEnd funcs after the reset but got %d", len(ctx.onEnd))
+	}
+	for i, fn := range onEnd[:cap(onEnd)][:2] {
+		if fn != nil {
+			t.Fatalf("expected the OnEnd func %d to be released by the reset", i)
+		}
+	}
+}
+
+func TestForwardAndExec(t *testing.T) {
+	userKey := NewContextKey("user")
+
+	s := newTestIris()
+	s.Get("/users/:id", func(ctx *Context) {
+		ctx.Write("%s user %s %s from %s", ctx.MethodString(), ctx.Param("id"), ctx.URLParam("tab"), userKey.GetString(ctx))
+		userKey.Set(ctx, "changed")
+	})
+	s.Post("/users/:id", func(ctx *Context) {
+		ctx.Write("POST user %s", ctx.Param("id"))
+	})
+	s.Get("/u/:id", func(ctx *Context) {
+		userKey.Set(ctx, "bob")
+		ctx.Forward("/users/" + ctx.Param("id"))
+		// the values which the new route sets are not copied back
+		ctx.Write(" %s", userKey.GetString(ctx))
+	})
+	s.Get("/create/:id", func(ctx *Context) {
+		ctx.Exec("POST", "/users/"+ctx.Param("id")+"?from=create")
+	})
+	s.Get("/loop", func(ctx *Context) {
+		if err := ctx.Forward("/loop"); err != nil {
+			ctx.Write("loop stopped")
+		}
+	})
+	testServe(s)
+
+	expectResponse(t, testRequest(s, "GET", "/u/42?tab=posts", ""), 200, "GET user 42 posts from bob bob")
+	expectResponse(t, testRequest(s, "GET", "/create/7", ""), 200, "POST user 7")
+	reqCtx := testRequest(s, "GET", "/loop", "")
+	if body := string(reqCtx.Response.Body()); !strings.HasSuffix(body, "loop stopped") {
+		t.Fatalf("expected the forward loop to be stopped by the maximum depth but got %q", body)
+	}
+}
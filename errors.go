@@ -38,6 +38,15 @@ var (
 	// ErrHandleAnnotated returns an error with message: 'HandleAnnotated parse: +specific error(s)'
 	ErrHandleAnnotated = errors.New("HandleAnnotated parse: %s")
 
+	// Server
+
+	// ErrListener returns an error with message: 'While trying to open the listener of '+addr'. Trace: +specific error'
+	ErrListener = errors.New("While trying to open the listener of '%s'. Trace: %s")
+	// ErrNoInheritedListeners returns an error with message: 'The process has no inherited listeners, LISTEN_FDS is not set'
+	ErrNoInheritedListeners = errors.New("The process has no inherited listeners, LISTEN_FDS is not set")
+	// ErrNoCertificate returns an error with message: 'The file '+filename' has no pem encoded certificate'
+	ErrNoCertificate = errors.New("The file '%s' has no pem encoded certificate")
+
 	// Plugin
 
 	// ErrPluginAlreadyExists returns an error with message: 'Cannot activate the same plugin again, plugin '+plugin name[+plugin description]' is already exists'
@@ -66,6 +75,11 @@ var (
 	// ErrTemplateExecute returns an error with message:'Unable to execute a template. Trace: +specific error'
 	ErrTemplateExecute = errors.New("Unable to execute a template. Trace: %s")
 
+	// ErrForwardDepth returns an error with message: 'Forward to '+path' exceeds the maximum depth of +depth, propably a forward loop'
+	ErrForwardDepth = errors.New("Forward to '%s' exceeds the maximum depth of %d, propably a forward loop")
+	// ErrContextKeyMissing returns an error with message: 'Context key '+key name' is missing from the request storage'
+	ErrContextKeyMissing = errors.New("Context key '%s' is missing from the request storage")
+
 	// ErrFlashNotFound returns an error with message: 'Unable to get flash message. Trace: Cookie does not exists'
 	ErrFlashNotFound = errors.New("Unable to get flash message. Trace: Cookie does not exists")
 )
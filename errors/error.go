@@ -44,9 +44,9 @@ func (e *Error) Error() string {
 	return e.message
 }
 
-// Format returns a formatted new error based on the arguments
+// Format returns a formatted new error based on the arguments, each argument is a verb of the message
 func (e *Error) Format(args ...interface{}) error {
-	return fmt.Errorf(e.message, args)
+	return fmt.Errorf(e.message, args...)
 }
 
 // With does the same thing as Format but it receives an error type which if it's nil it returns a nil error
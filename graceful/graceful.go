@@ -5,12 +5,14 @@ import (
 	"os"
 	"os/signal"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/kataras/iris"
 	"github.com/kataras/iris/logger"
 	"github.com/kataras/iris/server"
+	"github.com/valyala/fasthttp"
 	"golang.org/x/net/netutil"
 )
 
@@ -41,6 +43,15 @@ type Server struct {
 	// manually with Stop().
 	NoSignalHandling bool
 
+	// UpgradeSignal the signal which starts the Upgrade, the zero-downtime re-exec of the binary
+	// Default is SIGUSR2, nil on windows, nil disables it
+	UpgradeSignal os.Signal
+
+	// UpgradeTimeout is the duration to wait for the new process of the Upgrade to be ready,
+	// the new process is killed and this server keeps serving after that
+	// Default is 0, one minute
+	UpgradeTimeout time.Duration
+
 	// Logger used to notify of errors on startup and on stop.
 	Logger *logger.Logger
 
@@ -64,6 +75,12 @@ type Server struct {
 
 	// connections holds all connections managed by graceful
 	connections map[net.Conn]struct{}
+
+	// idleConnections holds all idle connections managed by graceful, they are closed on shutdown
+	idleConnections map[net.Conn]struct{}
+
+	// listener is the listener which is served, it's passed to the new process by the Upgrade
+	listener net.Listener
 }
 
 // Run serves the http.Handler with graceful shutdown enabled.
@@ -73,8 +90,9 @@ type Server struct {
 // we don't pass an iris.RequestHandler , because we need iris.station.server to be setted in order the station.Close() to work
 func Run(addr string, timeout time.Duration, n *iris.Iris) {
 	srv := &Server{
-		Timeout: timeout,
-		Logger:  DefaultLogger(),
+		Timeout:       timeout,
+		UpgradeSignal: defaultUpgradeSignal,
+		Logger:        DefaultLogger(),
 	}
 	srv.station = n
 	srv.Server = srv.station.DoPreListen(server.Config{ListeningAddr: addr})
@@ -93,8 +111,9 @@ func Run(addr string, timeout time.Duration, n *iris.Iris) {
 // return it instead.
 func RunWithErr(addr string, timeout time.Duration, n *iris.Iris) error {
 	srv := &Server{
-		Timeout: timeout,
-		Logger:  DefaultLogger(),
+		Timeout:       timeout,
+		UpgradeSignal: defaultUpgradeSignal,
+		Logger:        DefaultLogger(),
 	}
 	srv.station = n
 	srv.Server = srv.station.DoPreListen(server.Config{ListeningAddr: addr})
@@ -102,7 +121,19 @@ func RunWithErr(addr string, timeout time.Duration, n *iris.Iris) error {
 }
 
 // ListenAndServe is equivalent to iris.Listen with graceful shutdown enabled.
+// The listener which is inherited by the systemd socket activation or by the Upgrade of the parent process is served instead of the addr, if any.
 func (srv *Server) listenAndServe() error {
+	inherited, err := server.InheritedListeners()
+	if err != nil {
+		return err
+	}
+	if len(inherited) > 0 {
+		for _, l := range inherited[1:] {
+			l.Close()
+		}
+		return srv.serve(inherited[0])
+	}
+
 	// Create the listener so we can control their lifetime
 
 	addr := srv.Config.ListeningAddr
@@ -118,6 +149,7 @@ func (srv *Server) listenAndServe() error {
 
 // Serve is equivalent to iris.Server.Serve with graceful shutdown enabled.
 func (srv *Server) serve(listener net.Listener) error {
+	srv.listener = listener
 
 	if srv.ListenLimit != 0 {
 		listener = netutil.LimitListener(listener, srv.ListenLimit)
@@ -125,12 +157,42 @@ func (srv *Server) serve(listener net.Listener) error {
 
 	// Track connection state
 	add := make(chan net.Conn)
+	idle := make(chan net.Conn)
+	active := make(chan net.Conn)
 	remove := make(chan net.Conn)
+	managed := make(chan struct{})
+	// the fasthttp reports the StateActive of the pipelined requests only, so the connections are marked active by their reads
+	listener = &activeListener{Listener: listener, active: active, managed: managed}
+	srv.Server.ConnState = func(conn net.Conn, state fasthttp.ConnState) {
+		var ch chan net.Conn
+		switch state {
+		case fasthttp.StateNew:
+			ch = add
+		case fasthttp.StateActive:
+			ch = active
+		case fasthttp.StateIdle:
+			if c, ok := conn.(*activeConn); ok {
+				atomic.StoreInt32(&c.idle, 1)
+			}
+			ch = idle
+		case fasthttp.StateClosed, fasthttp.StateHijacked:
+			ch = remove
+		default:
+			return
+		}
+		select {
+		case ch <- conn:
+		case <-managed: // the connections are not managed anymore
+		}
+	}
 
 	// Manage open connections
 	shutdown := make(chan chan struct{})
 	kill := make(chan struct{})
-	go srv.manageConnections(add, remove, shutdown, kill)
+	go func() {
+		srv.manageConnections(add, idle, active, remove, shutdown, kill)
+		close(managed)
+	}()
 
 	interrupt := srv.interruptChan()
 	// Set up the interrupt handler
@@ -140,9 +202,31 @@ func (srv *Server) serve(listener net.Listener) error {
 	quitting := make(chan struct{})
 	go srv.handleInterrupt(interrupt, quitting, listener)
 
+	handler := srv.Server.Server.Handler
+	srv.Server.Server.Handler = func(reqCtx *fasthttp.RequestCtx) {
+		handler(reqCtx)
+		select {
+		case <-quitting:
+			// the keep-alive connections are closed after their response on shutdown
+			reqCtx.SetConnectionClose()
+		default:
+		}
+	}
+
+	if !srv.NoSignalHandling && srv.UpgradeSignal != nil {
+		upgrade := make(chan os.Signal, 1)
+		signal.Notify(upgrade, srv.UpgradeSignal)
+		defer signal.Stop(upgrade)
+		go srv.handleUpgrade(upgrade, quitting)
+	}
+
 	// Serve with graceful listener.
 	// Execution blocks here until listener.Close() is called, above.
 	srv.station.DoPostListen()
+	// the parent of an Upgrade waits for this, before its shutdown
+	if err := notifyReady(); err != nil {
+		srv.log("[IRIS GRACEFUL ERROR] %s", err.Error())
+	}
 	err := srv.Server.Serve(listener)
 	if err != nil {
 		// If the underlying listening is closed, Serve returns an error
@@ -196,15 +280,24 @@ func DefaultLogger() *logger.Logger {
 	return logger.New()
 }
 
-func (srv *Server) manageConnections(add, remove chan net.Conn, shutdown chan chan struct{}, kill chan struct{}) {
+func (srv *Server) manageConnections(add, idle, active, remove chan net.Conn, shutdown chan chan struct{}, kill chan struct{}) {
 	var done chan struct{}
 	srv.connections = map[net.Conn]struct{}{}
+	srv.idleConnections = map[net.Conn]struct{}{}
 	for {
 		select {
 		case conn := <-add:
 			srv.connections[conn] = struct{}{}
+		case conn := <-idle:
+			srv.idleConnections[conn] = struct{}{}
+			if done != nil { // the keep-alive connections are not waited, they are closed on shutdown
+				srv.closeConn(conn)
+			}
+		case conn := <-active:
+			delete(srv.idleConnections, conn)
 		case conn := <-remove:
 			delete(srv.connections, conn)
+			delete(srv.idleConnections, conn)
 			if done != nil && len(srv.connections) == 0 {
 				done <- struct{}{}
 				return
@@ -214,6 +307,9 @@ func (srv *Server) manageConnections(add, remove chan net.Conn, shutdown chan ch
 				done <- struct{}{}
 				return
 			}
+			for conn := range srv.idleConnections {
+				srv.closeConn(conn)
+			}
 		case <-kill:
 			for k := range srv.connections {
 				if err := k.Close(); err != nil {
@@ -225,6 +321,13 @@ func (srv *Server) manageConnections(add, remove chan net.Conn, shutdown chan ch
 	}
 }
 
+// closeConn closes a connection which is managed by graceful
+func (srv *Server) closeConn(conn net.Conn) {
+	if err := conn.Close(); err != nil {
+		srv.log("[IRIS GRACEFUL ERROR] %s", err.Error())
+	}
+}
+
 func (srv *Server) interruptChan() chan os.Signal {
 	srv.chanLock.Lock()
 	defer srv.chanLock.Unlock()
@@ -249,7 +352,6 @@ func (srv *Server) handleInterrupt(interrupt chan os.Signal, quitting chan struc
 		}
 
 		close(quitting)
-		srv.Server.DisableKeepalive = true
 		if err := listener.Close(); err != nil {
 			srv.log("[IRIS GRACEFUL ERROR] %s", err.Error())
 		}
@@ -289,3 +391,37 @@ func (srv *Server) shutdown(shutdown chan chan struct{}, kill chan struct{}) {
 	srv.station.Close()
 	srv.chanLock.Unlock()
 }
+
+// activeListener marks its connections active when a request is read from them, look activeConn
+type activeListener struct {
+	net.Listener
+	active  chan net.Conn
+	managed chan struct{}
+}
+
+func (l *activeListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &activeConn{Conn: conn, listener: l}, nil
+}
+
+// activeConn is a connection which is marked active, so it's not closed on shutdown, when the next request is read from it after it's idle
+type activeConn struct {
+	net.Conn
+	listener *activeListener
+	// idle is 1 from its StateIdle until its next read
+	idle int32
+}
+
+func (c *activeConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 && atomic.CompareAndSwapInt32(&c.idle, 1, 0) {
+		select {
+		case c.listener.active <- c:
+		case <-c.listener.managed:
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,120 @@
+package graceful
+
+import (
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/server"
+)
+
+// newTestServer returns a graceful server of a new station, without signal handling and logs,
+// the "/" responds with the process id and the "/slow" after the delay
+func newTestServer(timeout time.Duration, delay time.Duration) *Server {
+	config := iris.DefaultConfig()
+	config.Log = false
+	station := iris.New(config)
+	station.Get("/", func(ctx *iris.Context) {
+		ctx.Text(iris.StatusOK, strconv.Itoa(os.Getpid()))
+	})
+	station.Get("/slow", func(ctx *iris.Context) {
+		time.Sleep(delay)
+		ctx.Text(iris.StatusOK, "slow")
+	})
+
+	srv := &Server{Timeout: timeout, NoSignalHandling: true}
+	srv.station = station
+	srv.Server = station.DoPreListen(server.Config{ListeningAddr: "127.0.0.1:0"})
+	srv.Server.Server.Logger = log.New(ioutil.Discard, "", 0)
+	return srv
+}
+
+// serveTest serves the srv on a new listener, the error of the serve is sent to the returned channel
+func serveTest(t *testing.T, srv *Server) (string, chan error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.serve(l)
+	}()
+	return "http://" + l.Addr().String(), done
+}
+
+func get(url string) (string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	return string(body), err
+}
+
+func TestGracefulStop(t *testing.T) {
+	srv := newTestServer(5*time.Second, 200*time.Millisecond)
+	url, done := serveTest(t, srv)
+
+	if body, err := get(url + "/"); err != nil || body != strconv.Itoa(os.Getpid()) {
+		t.Fatalf("expected the process id but got %q, %v", body, err)
+	}
+
+	// the outstanding request is completed after the stop
+	slow := make(chan string, 1)
+	go func() {
+		body, err := get(url + "/slow")
+		if err != nil {
+			body = err.Error()
+		}
+		slow <- body
+	}()
+	time.Sleep(50 * time.Millisecond)
+	srv.Stop(5 * time.Second)
+
+	if body := <-slow; body != "slow" {
+		t.Fatalf("expected the outstanding request to be completed but got %q", body)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the serve to return after the stop")
+	}
+	if !srv.Interrupted {
+		t.Fatal("expected the server to be interrupted")
+	}
+
+	// the listener is closed
+	if _, err := get(url + "/"); err == nil {
+		t.Fatal("expected the new requests to fail after the stop")
+	}
+}
+
+func TestGracefulStopTimeout(t *testing.T) {
+	srv := newTestServer(0, 5*time.Second)
+	url, done := serveTest(t, srv)
+
+	go get(url + "/slow")
+	time.Sleep(50 * time.Millisecond)
+	start := time.Now()
+	// the outstanding request is killed after the timeout
+	srv.Stop(100 * time.Millisecond)
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected the serve to return after the timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("expected the outstanding request to be killed after the timeout but it took %s", elapsed)
+	}
+}
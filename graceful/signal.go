@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package graceful
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultUpgradeSignal the default signal of the Upgrade
+var defaultUpgradeSignal os.Signal = syscall.SIGUSR2
@@ -0,0 +1,6 @@
+package graceful
+
+import "os"
+
+// defaultUpgradeSignal the default signal of the Upgrade, there is no SIGUSR2 on windows, the Upgrade can be called directly
+var defaultUpgradeSignal os.Signal
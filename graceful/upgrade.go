@@ -0,0 +1,152 @@
+package graceful
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kataras/iris/errors"
+	"github.com/kataras/iris/server"
+)
+
+const (
+	// ReadyFDEnv the environment variable of the file descriptor which the new process of an Upgrade writes to when it's ready
+	ReadyFDEnv = "GRACEFUL_READY_FD"
+	// DefaultUpgradeTimeout the default duration to wait for the new process of an Upgrade to be ready
+	DefaultUpgradeTimeout = 1 * time.Minute
+)
+
+var (
+	// ErrUpgradeListener returns an error with message: 'Cannot pass the listener to the new process: +specific error'
+	ErrUpgradeListener = errors.New("Cannot pass the listener to the new process: %s")
+	// ErrUpgradeStart returns an error with message: 'Cannot start the new process: +specific error'
+	ErrUpgradeStart = errors.New("Cannot start the new process: %s")
+	// ErrUpgradeNotReady returns an error with message: 'The new process +pid is not ready: +specific error'
+	ErrUpgradeNotReady = errors.New("The new process %d is not ready: %s")
+)
+
+// filer is implemented by the *net.TCPListener and the *net.UnixListener
+type filer interface {
+	File() (*os.File, error)
+}
+
+// Upgrade starts a new process of the same executable, with the same arguments, which inherits the listener of this server,
+// the new process serves it by the Run, look server.InheritedListeners.
+// When the new process is ready the server is shut down gracefully, the outstanding requests are completed, so no connection is dropped.
+// If the new process cannot start or it's not ready in the UpgradeTimeout, it's killed and this server keeps serving.
+//
+// It's called on the UpgradeSignal, SIGUSR2 by default
+func (srv *Server) Upgrade() error {
+	l, ok := srv.listener.(filer)
+	if !ok {
+		return ErrUpgradeListener.Format("the listener has no file descriptor")
+	}
+	listenerFile, err := l.File() // a dup, the listener is not affected
+	if err != nil {
+		return ErrUpgradeListener.Format(err.Error())
+	}
+	defer listenerFile.Close()
+
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return ErrUpgradeStart.Format(err.Error())
+	}
+	defer readyReader.Close()
+
+	// the os.Executable needs go1.8, the binary is looked up by the name which it's started with
+	executable, err := exec.LookPath(os.Args[0])
+	if err != nil {
+		executable = os.Args[0]
+	}
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	// the ExtraFiles are the file descriptors 3, the listener, and 4, the readiness pipe
+	cmd.ExtraFiles = []*os.File{listenerFile, readyWriter}
+	cmd.Env = append(upgradeEnv(), server.ListenFDsEnv+"=1", ReadyFDEnv+"=4")
+	err = cmd.Start()
+	readyWriter.Close() // the child has its own
+	if err != nil {
+		return ErrUpgradeStart.Format(err.Error())
+	}
+
+	timeout := srv.UpgradeTimeout
+	if timeout <= 0 {
+		timeout = DefaultUpgradeTimeout
+	}
+	ready := make(chan error, 1)
+	go func() {
+		// the child writes one byte when it's ready, the read fails if the child exits before that
+		_, err := readyReader.Read(make([]byte, 1))
+		ready <- err
+	}()
+
+	reason := ""
+	select {
+	case err = <-ready:
+		if err != nil {
+			reason = "it exited, " + err.Error()
+		}
+	case <-time.After(timeout):
+		reason = "timeout after " + timeout.String()
+	}
+	if reason != "" {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return ErrUpgradeNotReady.Format(cmd.Process.Pid, reason)
+	}
+	go cmd.Wait()
+
+	srv.log("upgrade: the new process %d is ready, shutting down", cmd.Process.Pid)
+	srv.interruptChan() <- syscall.SIGTERM
+	return nil
+}
+
+// handleUpgrade upgrades the server on every signal, until the server is quitting
+func (srv *Server) handleUpgrade(upgrade chan os.Signal, quitting chan struct{}) {
+	for {
+		select {
+		case <-upgrade:
+			srv.log("upgrade initiated")
+			if err := srv.Upgrade(); err != nil {
+				srv.log("[IRIS GRACEFUL ERROR] %s", err.Error())
+			}
+		case <-quitting:
+			return
+		}
+	}
+}
+
+// upgradeEnv returns the environment of the new process of an Upgrade, without the variables of the inherited listeners of this process
+func upgradeEnv() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, server.ListenFDsEnv+"=") || strings.HasPrefix(kv, server.ListenPIDEnv+"=") ||
+			strings.HasPrefix(kv, "LISTEN_FDNAMES=") || strings.HasPrefix(kv, ReadyFDEnv+"=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
+// notifyReady notifies the parent process of an Upgrade that this process is ready, if it's started by an Upgrade
+func notifyReady() error {
+	fd := os.Getenv(ReadyFDEnv)
+	if fd == "" {
+		return nil
+	}
+	os.Unsetenv(ReadyFDEnv)
+
+	n, err := strconv.Atoi(fd)
+	if err != nil {
+		return ErrUpgradeListener.Format(ReadyFDEnv + "=" + fd + " is not a valid file descriptor")
+	}
+	f := os.NewFile(uintptr(n), "ready")
+	defer f.Close()
+	_, err = f.Write([]byte{1})
+	return err
+}
@@ -0,0 +1,147 @@
+//go:build !windows
+// +build !windows
+
+package graceful
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/server"
+)
+
+// TestMain runs the new process of the TestUpgrade, which is the test binary itself started by the Upgrade, instead of the tests
+func TestMain(m *testing.M) {
+	if os.Getenv(ReadyFDEnv) != "" {
+		srv := newTestServer(5*time.Second, 0)
+		// it's stopped by the TestUpgrade with a SIGTERM
+		srv.NoSignalHandling = false
+		if err := srv.listenAndServe(); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestUpgradeEnv(t *testing.T) {
+	vars := []string{server.ListenFDsEnv, server.ListenPIDEnv, "LISTEN_FDNAMES", ReadyFDEnv}
+	for _, name := range vars {
+		os.Setenv(name, "1")
+		defer os.Unsetenv(name)
+	}
+	os.Setenv("GRACEFUL_TEST", "kept")
+	defer os.Unsetenv("GRACEFUL_TEST")
+
+	kept := false
+	for _, kv := range upgradeEnv() {
+		for _, name := range vars {
+			if strings.HasPrefix(kv, name+"=") {
+				t.Fatalf("expected the %s to be removed from the environment of the new process", name)
+			}
+		}
+		kept = kept || kv == "GRACEFUL_TEST=kept"
+	}
+	if !kept {
+		t.Fatal("expected the rest of the environment to be kept")
+	}
+}
+
+func TestNotifyReady(t *testing.T) {
+	if err := notifyReady(); err != nil {
+		t.Fatalf("expected no notification without the %s but got %v", ReadyFDEnv, err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	// the notifyReady closes its file descriptor, the w keeps its own
+	fd, err := syscall.Dup(int(w.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	os.Setenv(ReadyFDEnv, strconv.Itoa(fd))
+	if err := notifyReady(); err != nil {
+		t.Fatal(err)
+	}
+	if os.Getenv(ReadyFDEnv) != "" {
+		t.Fatalf("expected the %s to be unset", ReadyFDEnv)
+	}
+	b := make([]byte, 2)
+	if n, err := r.Read(b); err != nil || n != 1 {
+		t.Fatalf("expected the ready byte but got %d bytes, %v", n, err)
+	}
+
+	os.Setenv(ReadyFDEnv, "x")
+	defer os.Unsetenv(ReadyFDEnv)
+	if err := notifyReady(); err == nil {
+		t.Fatal("expected the error of an invalid file descriptor")
+	}
+}
+
+func TestUpgradeListener(t *testing.T) {
+	srv := newTestServer(0, 0)
+	srv.listener = &activeListener{}
+	if err := srv.Upgrade(); err == nil {
+		t.Fatal("expected the error of a listener without a file descriptor")
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	srv := newTestServer(5*time.Second, 0)
+	srv.UpgradeTimeout = 30 * time.Second
+	url, done := serveTest(t, srv)
+
+	parentPid := strconv.Itoa(os.Getpid())
+	if body, err := get(url + "/"); err != nil || body != parentPid {
+		t.Fatalf("expected the process id %s but got %q, %v", parentPid, body, err)
+	}
+
+	if err := srv.Upgrade(); err != nil {
+		t.Fatal(err)
+	}
+	// this server is shut down after the new process is ready
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the server to be shut down after the upgrade")
+	}
+
+	// the new process serves the same listener
+	body, err := get(url + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	childPid, err := strconv.Atoi(body)
+	if err != nil || body == parentPid {
+		t.Fatalf("expected the process id of the new process but got %q", body)
+	}
+	if err := syscall.Kill(childPid, syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+
+	// the new process closes the listener on the SIGTERM
+	addr := strings.TrimPrefix(url, "http://")
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			break
+		}
+		conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("expected the new process to be stopped")
+		}
+	}
+}
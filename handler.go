@@ -47,6 +47,13 @@ type (
 	// Handler that calls f.
 	HandlerFunc func(*Context)
 
+	// HandlerFuncE is like HandlerFunc but it returns an error,
+	// a non-nil error is passed to the central error handler, look OnHandlerError
+	//
+	// It implements the Handler, so it can be passed to the .Use, .Handle and .Done directly,
+	// the routes of these handlers are registered with the .HandleFuncE, .GetE, .PostE...
+	HandlerFuncE func(*Context) error
+
 	//IMiddlewareSupporter is an interface which all routers must implement
 	IMiddlewareSupporter interface {
 		Use(handlers ...Handler)
@@ -62,13 +69,23 @@ func (h HandlerFunc) Serve(ctx *Context) {
 	h(ctx)
 }
 
-// ToHandler converts an http.Handler or http.HandlerFunc to an iris.Handler
+// Serve serves the handler and passes the returned error, if any, to the central error handler
+func (h HandlerFuncE) Serve(ctx *Context) {
+	if err := h(ctx); err != nil {
+		ctx.HandleError(err)
+	}
+}
+
+// ToHandler converts an http.Handler, http.HandlerFunc or func(*Context) error to an iris.Handler
 func ToHandler(handler interface{}) Handler {
 	//this is not the best way to do it, but I dont have any options right now.
 	switch handler.(type) {
 	case Handler:
 		//it's already an iris handler
 		return handler.(Handler)
+	case func(*Context) error:
+		//it's an iris handler which returns an error
+		return HandlerFuncE(handler.(func(*Context) error))
 	case http.Handler:
 		//it's http.Handler
 		h := fasthttpadaptor.NewFastHTTPHandlerFunc(handler.(http.Handler).ServeHTTP)
@@ -83,7 +100,7 @@ func ToHandler(handler interface{}) Handler {
 	}
 }
 
-// ToHandlerFunc converts an http.Handler or http.HandlerFunc to an iris.HandlerFunc
+// ToHandlerFunc converts an http.Handler, http.HandlerFunc or func(*Context) error to an iris.HandlerFunc
 func ToHandlerFunc(handler interface{}) HandlerFunc {
 	return ToHandler(handler).Serve
 }
@@ -106,6 +123,15 @@ func ConvertToHandlers(handlersFn []HandlerFunc) []Handler {
 	return mlist
 }
 
+// ConvertToHandlersE accepts list of HandlerFuncE and returns list of Handler
+func ConvertToHandlersE(handlersFn []HandlerFuncE) []Handler {
+	mlist := make([]Handler, len(handlersFn))
+	for i := range handlersFn {
+		mlist[i] = handlersFn[i]
+	}
+	return mlist
+}
+
 // JoinMiddleware uses to create a copy of all middleware and return them in order to use inside the node
 func JoinMiddleware(middleware1 Middleware, middleware2 Middleware) Middleware {
 	nowLen := len(middleware1)
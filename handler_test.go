@@ -0,0 +1,152 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"errors"
+	"testing"
+)
+
+var errTestNotFound = errors.New("user not found")
+
+type testConflictError struct{ name string }
+
+func (e testConflictError) Error() string {
+	return e.name + " already exists"
+}
+
+type testCoderError struct{}
+
+func (testCoderError) Error() string {
+	return "payment required"
+}
+
+func (testCoderError) StatusCode() int {
+	return StatusPaymentRequired
+}
+
+func TestHandlerFuncE(t *testing.T) {
+	s := newTestIris()
+	s.MapError(errTestNotFound, StatusNotFound)
+	s.MapErrorType(testConflictError{}, StatusConflict)
+
+	s.GetE("/ok", func(ctx *Context) error {
+		return ctx.Text(StatusOK, "ok")
+	})
+	s.GetE("/value", func(ctx *Context) error {
+		return errTestNotFound
+	})
+	s.PostE("/type", func(ctx *Context) error {
+		return testConflictError{name: "kataras"}
+	})
+	s.AnyE("/coder", func(ctx *Context) error {
+		return testCoderError{}
+	})
+	s.HandleFuncE(MethodGet, "/unmapped", func(ctx *Context) error {
+		return errors.New("unexpected")
+	})
+	// the handlers after the one which returned an error are not executed
+	s.GetE("/chain", func(ctx *Context) error {
+		return errTestNotFound
+	}, func(ctx *Context) error {
+		return ctx.Text(StatusOK, "next")
+	})
+	s.Get("/converted", ToHandlerFunc(func(ctx *Context) error {
+		return testConflictError{}
+	}))
+	testServe(s)
+
+	expectResponse(t, testRequest(s, MethodGet, "/ok", ""), StatusOK, "ok")
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{MethodGet, "/value", StatusNotFound},
+		{MethodPost, "/type", StatusConflict},
+		{MethodGet, "/coder", StatusPaymentRequired},
+		{MethodDelete, "/coder", StatusPaymentRequired},
+		{MethodGet, "/unmapped", StatusInternalServerError},
+		{MethodGet, "/chain", StatusNotFound},
+		{MethodGet, "/converted", StatusConflict},
+	}
+	for _, tt := range tests {
+		reqCtx := testRequest(s, tt.method, tt.path, "")
+		if status := reqCtx.Response.StatusCode(); status != tt.status {
+			t.Fatalf("%s %s: expected status code %d but got %d", tt.method, tt.path, tt.status, status)
+		}
+		if body := string(reqCtx.Response.Body()); body == "next" {
+			t.Fatalf("%s %s: the next handler was executed after an error", tt.method, tt.path)
+		}
+	}
+}
+
+func TestOnHandlerError(t *testing.T) {
+	s := newTestIris()
+	s.MapError(errTestNotFound, StatusNotFound)
+	s.OnHandlerError(func(ctx *Context, err error) {
+		ctx.Text(s.ErrorStatusCode(err), "custom: "+err.Error())
+	})
+	s.UseFunc(ToHandlerFunc(func(ctx *Context) error {
+		if ctx.URLParam("fail") != "" {
+			return errTestNotFound
+		}
+		ctx.Next()
+		return nil
+	}))
+	s.GetE("/", func(ctx *Context) error {
+		return ctx.Text(StatusOK, "index")
+	})
+	testServe(s)
+
+	expectResponse(t, testRequest(s, MethodGet, "/", ""), StatusOK, "index")
+	expectResponse(t, testRequest(s, MethodGet, "/?fail=1", ""), StatusNotFound, "custom: user not found")
+}
+
+func TestErrorStatusCode(t *testing.T) {
+	s := newTestIris()
+	s.MapError(errTestNotFound, StatusNotFound)
+	s.MapErrorType(testConflictError{}, StatusConflict)
+
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{nil, StatusOK},
+		{errTestNotFound, StatusNotFound},
+		{testConflictError{name: "a"}, StatusConflict},
+		{testCoderError{}, StatusPaymentRequired},
+		{errors.New("other"), StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if status := s.ErrorStatusCode(tt.err); status != tt.status {
+			t.Fatalf("%v: expected status code %d but got %d", tt.err, tt.status, status)
+		}
+	}
+}
@@ -27,6 +27,14 @@
 
 package iris
 
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
 //taken from net/http
 const (
 	StatusContinue           = 100
@@ -47,6 +55,7 @@ const (
 	StatusNotModified       = 304
 	StatusUseProxy          = 305
 	StatusTemporaryRedirect = 307
+	StatusPermanentRedirect = 308
 
 	StatusBadRequest                   = 400
 	StatusUnauthorized                 = 401
@@ -100,6 +109,7 @@ var statusText = map[int]string{
 	StatusNotModified:       "Not Modified",
 	StatusUseProxy:          "Use Proxy",
 	StatusTemporaryRedirect: "Temporary Redirect",
+	StatusPermanentRedirect: "Permanent Redirect",
 
 	StatusBadRequest:                   "Bad Request",
 	StatusUnauthorized:                 "Unauthorized",
@@ -150,6 +160,29 @@ type (
 		handler HandlerFunc
 	}
 
+	// HTTPErrorPage is the binding of the error templates, look IrisConfig.ErrorTemplates
+	HTTPErrorPage struct {
+		// StatusCode the http status code, i.e 404
+		StatusCode int
+		// Status the text of the status code, i.e "Not Found"
+		Status string
+		// Message the message of the default error handler
+		Message string
+		// Path the request path
+		Path string
+		// Error the error which caused the http error, if any, it's empty unless the Render.IsDevelopment is true
+		Error string
+	}
+
+	// ErrorHandler is the central handler of the errors which are returned by the HandlerFuncE handlers
+	ErrorHandler func(ctx *Context, err error)
+
+	// ErrorStatusCoder is the interface which an error can implement in order to be mapped to a specific http status code
+	// by the central error handler
+	ErrorStatusCoder interface {
+		StatusCode() int
+	}
+
 	// HTTPErrorContainer is the struct which contains the handlers which will execute if http error occurs
 	// One struct per Server instance, the meaning of this is that the developer can change the default error message and replace them with his/her own completely custom handlers
 	//
@@ -160,6 +193,14 @@ type (
 	HTTPErrorContainer struct {
 		// Errors contains all the httperrorhandlers
 		Errors []*HTTPErrorHandler
+		// errorHandler the custom central error handler, if nil then the errors are logged and emitted by their status code
+		errorHandler ErrorHandler
+		// errorCodes maps error values to http status codes
+		errorCodes map[error]int
+		// errorTypeCodes maps error types to http status codes
+		errorTypeCodes map[reflect.Type]int
+		// problemPaths the path prefixes of which the default http errors are problem details objects, look ProblemErrorsFor
+		problemPaths []string
 	}
 )
 
@@ -170,6 +211,98 @@ func HTTPErrorHandlerFunc(statusCode int, message string) HandlerFunc {
 	}
 }
 
+// ErrorKey is the ContextKey of the error which is handled by the central error handler or recovered by the recovery middleware,
+// the http error handlers can use it, i.e err, ok := iris.ErrorKey.Get(ctx)
+var ErrorKey = NewContextKey("error")
+
+// errorPageHandlerFunc creates the default handler of a http error,
+// it renders the error template of the status code, if any, otherwise it sends the message as text
+func errorPageHandlerFunc(statusCode int, message string) HandlerFunc {
+	return func(ctx *Context) {
+		if ctx.station.isProblemPath(ctx.HostString(), ctx.PathString()) {
+			ctx.problemError(statusCode, message)
+			return
+		}
+		if !ctx.renderErrorPage(statusCode, message) {
+			ctx.Text(statusCode, message)
+		}
+	}
+}
+
+// problemError sends the http error as a problem details object,
+// if the handled error (look ErrorKey) is a Problem then it's sent as it is
+func (ctx *Context) problemError(statusCode int, message string) {
+	problem := NewProblem(statusCode, message)
+	if err, ok := ErrorKey.Get(ctx); ok {
+		switch p := err.(type) {
+		case Problem:
+			problem = p
+		case *Problem:
+			problem = *p
+		default:
+			if config := ctx.station.Config; config.Render != nil && config.Render.IsDevelopment {
+				problem.Extensions = map[string]interface{}{"error": fmt.Sprintf("%v", err)}
+			}
+		}
+	}
+	if problem.Instance == "" {
+		problem.Instance = ctx.PathString()
+	}
+
+	if err := ctx.Problem(statusCode, problem); err != nil {
+		ctx.station.Logger.Printf("Problem %d: %s", statusCode, err.Error())
+		ctx.Text(statusCode, message)
+	}
+}
+
+// errorTemplateName returns the name of the error template of the status code, without the extension
+func errorTemplateName(pattern string, statusCode int, extensions []string) string {
+	code := strconv.Itoa(statusCode)
+	name := path.Join(pattern, code)
+	if strings.Contains(pattern, "{code}") {
+		name = strings.Replace(pattern, "{code}", code, -1)
+	}
+
+	for _, ext := range extensions {
+		if strings.HasSuffix(name, ext) {
+			return name[0 : len(name)-len(ext)]
+		}
+	}
+	return name
+}
+
+// renderErrorPage renders the error template of the status code, returns false if the IrisConfig.ErrorTemplates is empty or there is no template
+func (ctx *Context) renderErrorPage(statusCode int, message string) bool {
+	config := ctx.station.Config
+	r := ctx.renderer()
+	if config.ErrorTemplates == "" || config.Render == nil || r == nil {
+		return false
+	}
+
+	name := errorTemplateName(config.ErrorTemplates, statusCode, config.Render.Extensions)
+	if !r.Engine().Lookup(name) {
+		return false
+	}
+
+	page := HTTPErrorPage{
+		StatusCode: statusCode,
+		Status:     StatusText(statusCode),
+		Message:    message,
+		Path:       ctx.PathString(),
+	}
+	if config.Render.IsDevelopment {
+		if err, ok := ErrorKey.Get(ctx); ok {
+			page.Error = fmt.Sprintf("%v", err)
+		}
+	}
+
+	if err := ctx.HTML(statusCode, name, page); err != nil {
+		ctx.station.Logger.Printf("Error template %s: %s", name, err.Error())
+		return false
+	}
+	return true
+}
+
 // GetCode returns the http status code value
 func (e *HTTPErrorHandler) GetCode() int {
 	return e.code
@@ -189,8 +322,8 @@ func (e *HTTPErrorHandler) SetHandler(h HandlerFunc) {
 func defaultHTTPErrors() *HTTPErrorContainer {
 	httperrors := new(HTTPErrorContainer)
 	httperrors.Errors = make([]*HTTPErrorHandler, 0)
-	httperrors.OnError(StatusNotFound, HTTPErrorHandlerFunc(StatusNotFound, "404 not found"))
-	httperrors.OnError(StatusInternalServerError, HTTPErrorHandlerFunc(StatusInternalServerError, "The server encountered an unexpected condition which prevented it from fulfilling the request."))
+	httperrors.OnError(StatusNotFound, errorPageHandlerFunc(StatusNotFound, "404 not found"))
+	httperrors.OnError(StatusInternalServerError, errorPageHandlerFunc(StatusInternalServerError, "The server encountered an unexpected condition which prevented it from fulfilling the request."))
 	return httperrors
 }
 
@@ -233,13 +366,33 @@ func (he *HTTPErrorContainer) EmitError(errCode int, ctx *Context) {
 		errHandler.GetHandler().Serve(ctx)
 	} else {
 		//if no error is registed, then register it with the default http error text, and re-run the Emit
-		he.OnError(errCode, func(c *Context) {
-			c.Text(errCode, StatusText(errCode))
-		})
+		he.OnError(errCode, errorPageHandlerFunc(errCode, StatusText(errCode)))
 		he.EmitError(errCode, ctx)
 	}
 }
 
+// ProblemErrorsFor makes the default http errors of the requests under the path prefix problem details objects (RFC 7807),
+// i.e "/api", instead of text or error templates, a domain (i.e "api.mydomain.com") can be used too. Look Party.ProblemErrors.
+// The handlers which are registed by the OnError are not affected.
+func (he *HTTPErrorContainer) ProblemErrorsFor(pathPrefix string) {
+	he.problemPaths = append(he.problemPaths, pathPrefix)
+}
+
+// isProblemPath returns true if the default http errors of the request path are problem details objects
+func (he *HTTPErrorContainer) isProblemPath(host string, reqPath string) bool {
+	for _, prefix := range he.problemPaths {
+		p := reqPath
+		if prefix == "" || prefix[0] != SlashByte { // it's a domain
+			p = host + reqPath
+		}
+		prefix = strings.TrimSuffix(prefix, Slash)
+		if prefix == "" || p == prefix || strings.HasPrefix(p, prefix+Slash) {
+			return true
+		}
+	}
+	return false
+}
+
 // OnNotFound sets the handler for http status 404,
 // default is a response with text: 'Not Found' and status: 404
 func (he *HTTPErrorContainer) OnNotFound(handlerFunc HandlerFunc) {
@@ -251,3 +404,69 @@ func (he *HTTPErrorContainer) OnNotFound(handlerFunc HandlerFunc) {
 func (he *HTTPErrorContainer) OnPanic(handlerFunc HandlerFunc) {
 	he.OnError(StatusInternalServerError, handlerFunc)
 }
+
+// OnHandlerError sets the central error handler, which receives the errors returned by the HandlerFuncE handlers
+// by default the errors are logged through the Iris.Logger and the http error of their status code is emitted
+// use the .ErrorStatusCode(err) inside a custom handler to find the status code of an error
+func (he *HTTPErrorContainer) OnHandlerError(handler ErrorHandler) {
+	he.errorHandler = handler
+}
+
+// MapError maps an error value to a http status code,
+// used by the central error handler when a handler returns this error
+func (he *HTTPErrorContainer) MapError(err error, httpStatus int) {
+	if he.errorCodes == nil {
+		he.errorCodes = make(map[error]int)
+	}
+	he.errorCodes[err] = httpStatus
+}
+
+// MapErrorType maps the type of an error to a http status code,
+// used by the central error handler when a handler returns an error of the same type
+func (he *HTTPErrorContainer) MapErrorType(err error, httpStatus int) {
+	if he.errorTypeCodes == nil {
+		he.errorTypeCodes = make(map[reflect.Type]int)
+	}
+	he.errorTypeCodes[reflect.TypeOf(err)] = httpStatus
+}
+
+// ErrorStatusCode returns the http status code of an error
+// first checks the errors which are mapped with the .MapError, then the error types which are mapped with the .MapErrorType,
+// then if the error implements the ErrorStatusCoder, if none of these then it returns 500
+func (he *HTTPErrorContainer) ErrorStatusCode(err error) int {
+	if err == nil {
+		return StatusOK
+	}
+
+	if reflect.TypeOf(err).Comparable() {
+		if code, ok := he.errorCodes[err]; ok {
+			return code
+		}
+	}
+
+	if code, ok := he.errorTypeCodes[reflect.TypeOf(err)]; ok {
+		return code
+	}
+
+	if coder, ok := err.(ErrorStatusCoder); ok {
+		if code := coder.StatusCode(); code > 0 {
+			return code
+		}
+	}
+
+	return StatusInternalServerError
+}
+
+// HandleError passes an error to the central error handler, it's called when a HandlerFuncE returns an error
+func (he *HTTPErrorContainer) HandleError(err error, ctx *Context) {
+	ctx.StopExecution()
+	if he.errorHandler != nil {
+		he.errorHandler(ctx, err)
+		return
+	}
+
+	statusCode := he.ErrorStatusCode(err)
+	ErrorKey.Set(ctx, err)
+	ctx.station.Logger.Printf("%d %s %s: %s", statusCode, ctx.MethodString(), ctx.PathString(), err.Error())
+	he.EmitError(statusCode, ctx)
+}
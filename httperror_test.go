@@ -0,0 +1,117 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestErrorTemplateName(t *testing.T) {
+	extensions := []string{".html"}
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{"errors", "errors/404"},
+		{"errors/", "errors/404"},
+		{"errors/{code}", "errors/404"},
+		{"errors/{code}.html", "errors/404"},
+		{"{code}.html", "404"},
+	}
+	for _, tt := range tests {
+		if name := errorTemplateName(tt.pattern, StatusNotFound, extensions); name != tt.expected {
+			t.Fatalf("%s: expected %q but got %q", tt.pattern, tt.expected, name)
+		}
+	}
+}
+
+func newTestErrorTemplatesIris(isDevelopment bool) *Iris {
+	s := newTestTemplatesIris(map[string][]byte{
+		"errors/404.html": []byte(`<h1>{{.StatusCode}} {{.Status}}</h1><p>{{.Path}}</p>`),
+		"errors/500.html": []byte(`<h1>{{.StatusCode}}</h1><p>{{.Message}}</p><pre>{{.Error}}</pre>`),
+	})
+	s.Config.Render.IsDevelopment = isDevelopment
+	s.Config.ErrorTemplates = "errors/{code}.html"
+	s.GetE("/fail", func(ctx *Context) error {
+		return errors.New("database is down")
+	})
+	s.Get("/forbidden", func(ctx *Context) {
+		ctx.EmitError(StatusForbidden)
+	})
+	return s
+}
+
+func TestErrorTemplates(t *testing.T) {
+	s := newTestErrorTemplatesIris(false)
+	testServe(s)
+
+	reqCtx := testRequest(s, MethodGet, "/missing", "")
+	expectResponse(t, reqCtx, StatusNotFound, "<h1>404 Not Found</h1><p>/missing</p>")
+	if contentType := string(reqCtx.Response.Header.ContentType()); !strings.HasPrefix(contentType, ContentHTML) {
+		t.Fatalf("expected a html error page but got %q", contentType)
+	}
+
+	// the error is hidden on production
+	reqCtx = testRequest(s, MethodGet, "/fail", "")
+	expectResponse(t, reqCtx, StatusInternalServerError,
+		"<h1>500</h1><p>The server encountered an unexpected condition which prevented it from fulfilling the request.</p><pre></pre>")
+
+	// the status codes without a template are sent as text
+	reqCtx = testRequest(s, MethodGet, "/forbidden", "")
+	expectResponse(t, reqCtx, StatusForbidden, StatusText(StatusForbidden))
+}
+
+func TestErrorTemplatesDevelopment(t *testing.T) {
+	s := newTestErrorTemplatesIris(true)
+	testServe(s)
+
+	reqCtx := testRequest(s, MethodGet, "/fail", "")
+	expectResponse(t, reqCtx, StatusInternalServerError,
+		"<h1>500</h1><p>The server encountered an unexpected condition which prevented it from fulfilling the request.</p><pre>database is down</pre>")
+}
+
+func TestErrorTemplatesOnError(t *testing.T) {
+	s := newTestErrorTemplatesIris(false)
+	// the handlers of the OnError are not affected by the templates
+	s.OnError(StatusNotFound, func(ctx *Context) {
+		ctx.Text(StatusNotFound, "custom not found")
+	})
+	testServe(s)
+
+	expectResponse(t, testRequest(s, MethodGet, "/missing", ""), StatusNotFound, "custom not found")
+}
+
+func TestErrorTemplatesDisabled(t *testing.T) {
+	s := newTestErrorTemplatesIris(false)
+	s.Config.ErrorTemplates = ""
+	testServe(s)
+
+	expectResponse(t, testRequest(s, MethodGet, "/missing", ""), StatusNotFound, "404 not found")
+}
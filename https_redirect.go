@@ -0,0 +1,186 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"strings"
+
+	"github.com/kataras/iris/server"
+	"github.com/valyala/fasthttp"
+)
+
+// HTTPSRedirectConfig the config of the plain http listener which is opened with the ListenTLS and redirects the requests to https,
+// the host, the path and the query of the request are kept.
+// The host of the request is redirected only if it's one of the names of the ListenTLS's certificate, otherwise the response is 400,
+// so the redirect listener can't be used to redirect the clients to other hosts (open redirect), look Host
+type HTTPSRedirectConfig struct {
+	// Addr the addr of the plain http listener
+	// Default is ":80"
+	Addr string
+	// StatusCode the status code of the redirects
+	// Default is 0, the GET and HEAD requests are redirected with 301 and the rest with 308, which keeps their method and body
+	StatusCode int
+	// Host the host of the https location, without the port, i.e "example.com", all the requests are redirected to it,
+	// useful when the certificate has wildcard names only or the clients use a host which is not in the certificate
+	// Default is "", the host of the request, if it's one of the names of the ListenTLS's certificate
+	Host string
+	// Port the port of the https location, i.e "8443"
+	// Default is "", the port of the ListenTLS's addr, which is omitted if it's 443
+	Port string
+	// Exclude the request path prefixes which are served by the router, over plain http, instead of being redirected,
+	// i.e "/.well-known/" for the ACME challenges or "/health" for the health checks
+	Exclude []string
+}
+
+// addHTTPSRedirect adds the redirect listener of the Config.HTTPSRedirect, if not nil, for the https addr and the certificate of the ListenTLS
+func (s *Iris) addHTTPSRedirect(tlsAddr string, certFile string) {
+	c := s.Config.HTTPSRedirect
+	if c == nil || s.router.optimized {
+		return
+	}
+
+	addr := c.Addr
+	if addr == "" {
+		addr = ":80"
+	}
+	port := c.Port
+	if port == "" {
+		if _, tlsPort, err := net.SplitHostPort(tlsAddr); err == nil {
+			port = tlsPort
+		} else if strings.IndexByte(tlsAddr, ':') == -1 { // only the port
+			port = tlsAddr
+		}
+	}
+	if port == "443" {
+		port = ""
+	}
+
+	var names []string
+	if c.Host == "" {
+		// a certificate which can't be read fails the ListenTLS too
+		names, _ = certificateNames(certFile)
+	}
+
+	s.listeners = append(s.listeners, listener{config: server.Config{ListeningAddr: addr}, handler: s.httpsRedirectHandler(c, port, names)})
+}
+
+// httpsRedirectHandler returns the handler of the redirect listener, the excluded paths are served by the router,
+// the hosts of the requests are redirected only if they match one of the names, if the Host of the config is empty
+func (s *Iris) httpsRedirectHandler(c *HTTPSRedirectConfig, port string, names []string) fasthttp.RequestHandler {
+	return func(reqCtx *fasthttp.RequestCtx) {
+		reqPath := string(reqCtx.Path())
+		for _, excluded := range c.Exclude {
+			excluded = strings.TrimSuffix(excluded, Slash)
+			if reqPath == excluded || strings.HasPrefix(reqPath, excluded+Slash) {
+				s.router.ServeRequest(reqCtx)
+				return
+			}
+		}
+
+		host := string(reqCtx.Host())
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[]")
+		if c.Host != "" {
+			host = c.Host
+		} else if !matchCertificateName(names, host) {
+			// the host is not served by the https listener, the client is not redirected to it
+			reqCtx.Error(StatusText(StatusBadRequest), StatusBadRequest)
+			return
+		}
+		if port != "" {
+			host = net.JoinHostPort(host, port)
+		} else if strings.IndexByte(host, ':') >= 0 { // ipv6
+			host = "[" + host + "]"
+		}
+
+		statusCode := c.StatusCode
+		if statusCode <= 0 {
+			statusCode = StatusPermanentRedirect
+			if reqCtx.IsGet() || reqCtx.IsHead() {
+				statusCode = StatusMovedPermanently
+			}
+		}
+
+		// the URI's RequestURI is the path and the query even if the request line has the absolute form, i.e "GET http://host/path"
+		reqCtx.Response.Header.Set("Location", "https://"+host+string(reqCtx.URI().RequestURI()))
+		reqCtx.SetStatusCode(statusCode)
+	}
+}
+
+// certificateNames returns the lowercase DNS names, the common name and the ip addresses of the (first) certificate of the pem encoded certFile
+func certificateNames(certFile string) ([]string, error) {
+	contents, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	for block, rest := pem.Decode(contents); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		names := append([]string(nil), cert.DNSNames...)
+		if cert.Subject.CommonName != "" {
+			names = append(names, cert.Subject.CommonName)
+		}
+		for _, ip := range cert.IPAddresses {
+			names = append(names, ip.String())
+		}
+		for i := range names {
+			names[i] = strings.ToLower(names[i])
+		}
+		return names, nil
+	}
+	return nil, ErrNoCertificate.Format(certFile)
+}
+
+// matchCertificateName returns true if the host is one of the names of a certificate, the *.example.com matches the sub.example.com
+func matchCertificateName(names []string, host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if ip := net.ParseIP(host); ip != nil {
+		host = ip.String()
+	}
+	wildcard := ""
+	if idx := strings.IndexByte(host, '.'); idx > 0 {
+		wildcard = "*" + host[idx:]
+	}
+	for _, name := range names {
+		if name == host || name == wildcard {
+			return true
+		}
+	}
+	return false
+}
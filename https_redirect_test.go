@@ -0,0 +1,219 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/server"
+	"github.com/valyala/fasthttp"
+)
+
+// testRedirect executes a request to the example.com with the handler of the redirect listener, in-memory,
+// the uri can have the absolute form too
+func testRedirect(s *Iris, method string, uri string) *fasthttp.RequestCtx {
+	return testRedirectHost(s, method, "example.com", uri)
+}
+
+// testRedirectHost same as testRedirect but the request is sent to the host
+func testRedirectHost(s *Iris, method string, host string, uri string) *fasthttp.RequestCtx {
+	var req fasthttp.Request
+	req.Header.SetMethod(method)
+	req.Header.SetHost(host)
+	req.SetRequestURI(uri)
+
+	reqCtx := new(fasthttp.RequestCtx)
+	reqCtx.Init(&req, &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 52000}, nil)
+	s.Servers[len(s.Servers)-1].Handler()(reqCtx)
+	return reqCtx
+}
+
+// writeTestCertificate writes a self-signed certificate of the names, DNS names or ip addresses, to the dir and returns its filename
+func writeTestCertificate(t *testing.T, dir string, names ...string) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: names[0]},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	for _, name := range names {
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, name)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, "server.crt")
+	if err = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return certFile
+}
+
+// newTestRedirectIris returns an Iris with the redirect listener of the c, for the tlsAddr and a certificate of the example.com and the ::1
+func newTestRedirectIris(t *testing.T, c *HTTPSRedirectConfig, tlsAddr string) *Iris {
+	dir, err := ioutil.TempDir("", "iris-redirect")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := newTestIris()
+	s.Config.HTTPSRedirect = c
+	s.Get("/.well-known/acme-challenge/:token", func(ctx *Context) {
+		ctx.Text(StatusOK, ctx.Param("token"))
+	})
+	s.addHTTPSRedirect(tlsAddr, writeTestCertificate(t, dir, "example.com", "*.example.org", "::1"))
+	s.DoPreListen(server.Config{ListeningAddr: tlsAddr})
+	s.DoPostListen()
+	return s
+}
+
+func TestHTTPSRedirect(t *testing.T) {
+	s := newTestRedirectIris(t, &HTTPSRedirectConfig{Addr: "127.0.0.1:8080", Exclude: []string{"/.well-known/"}}, ":8443")
+	if len(s.Servers) != 2 || s.Servers[1].Config.ListeningAddr != "127.0.0.1:8080" {
+		t.Fatalf("expected the redirect listener on 127.0.0.1:8080 but got %d servers", len(s.Servers))
+	}
+
+	tests := []struct {
+		method   string
+		uri      string
+		status   int
+		location string
+	}{
+		{MethodGet, "/users?id=1", StatusMovedPermanently, "https://example.com:8443/users?id=1"},
+		{MethodGet, "http://example.com/users?id=1", StatusMovedPermanently, "https://example.com:8443/users?id=1"},
+		{MethodHead, "http://example.com:8080/", StatusMovedPermanently, "https://example.com:8443/"},
+		{MethodPost, "http://example.com:8080/users", StatusPermanentRedirect, "https://example.com:8443/users"},
+		{MethodGet, "http://[::1]:8080/", StatusMovedPermanently, "https://[::1]:8443/"},
+	}
+	for _, tt := range tests {
+		reqCtx := testRedirect(s, tt.method, tt.uri)
+		if status := reqCtx.Response.StatusCode(); status != tt.status {
+			t.Fatalf("%s %s: expected status code %d but got %d", tt.method, tt.uri, tt.status, status)
+		}
+		if location := string(reqCtx.Response.Header.Peek("Location")); location != tt.location {
+			t.Fatalf("%s %s: expected the location %s but got %s", tt.method, tt.uri, tt.location, location)
+		}
+	}
+
+	// the excluded paths are served by the router over plain http
+	expectResponse(t, testRedirect(s, MethodGet, "http://example.com/.well-known/acme-challenge/abc"), StatusOK, "abc")
+}
+
+func TestHTTPSRedirectHost(t *testing.T) {
+	s := newTestRedirectIris(t, &HTTPSRedirectConfig{}, ":443")
+	tests := []struct {
+		host     string
+		status   int
+		location string
+	}{
+		{"example.com", StatusMovedPermanently, "https://example.com/path"},
+		{"EXAMPLE.com.", StatusMovedPermanently, "https://example.com./path"},
+		{"api.example.org:80", StatusMovedPermanently, "https://api.example.org/path"},
+		{"[::1]", StatusMovedPermanently, "https://[::1]/path"},
+		// the hosts which are not in the certificate are not redirected
+		{"evil.com", StatusBadRequest, ""},
+		{"example.com.evil.com", StatusBadRequest, ""},
+		{"a.b.example.org", StatusBadRequest, ""},
+		{"127.0.0.1", StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		reqCtx := testRedirectHost(s, MethodGet, tt.host, "/path")
+		if status := reqCtx.Response.StatusCode(); status != tt.status {
+			t.Fatalf("%s: expected status code %d but got %d", tt.host, tt.status, status)
+		}
+		if location := string(reqCtx.Response.Header.Peek("Location")); location != tt.location {
+			t.Fatalf("%s: expected the location %q but got %q", tt.host, tt.location, location)
+		}
+	}
+
+	// all the requests are redirected to the configured host
+	s = newTestRedirectIris(t, &HTTPSRedirectConfig{Host: "www.example.com", Port: "8443"}, ":443")
+	for _, host := range []string{"evil.com", "example.com"} {
+		reqCtx := testRedirectHost(s, MethodGet, host, "/path?q=1")
+		if location := string(reqCtx.Response.Header.Peek("Location")); location != "https://www.example.com:8443/path?q=1" {
+			t.Fatalf("%s: expected the location of the configured host but got %q", host, location)
+		}
+	}
+}
+
+func TestHTTPSRedirectConfig(t *testing.T) {
+	tests := []struct {
+		config   *HTTPSRedirectConfig
+		tlsAddr  string
+		addr     string
+		status   int
+		location string
+	}{
+		// the default port of the https is omitted
+		{&HTTPSRedirectConfig{}, ":443", ":80", StatusMovedPermanently, "https://example.com/path"},
+		{&HTTPSRedirectConfig{}, "mydomain.com:443", ":80", StatusMovedPermanently, "https://example.com/path"},
+		// only the port
+		{&HTTPSRedirectConfig{Addr: ":8080"}, "8443", ":8080", StatusMovedPermanently, "https://example.com:8443/path"},
+		{&HTTPSRedirectConfig{Port: "9443", StatusCode: StatusTemporaryRedirect}, ":8443", ":80", StatusTemporaryRedirect, "https://example.com:9443/path"},
+	}
+	for _, tt := range tests {
+		s := newTestRedirectIris(t, tt.config, tt.tlsAddr)
+		if addr := s.Servers[1].Config.ListeningAddr; addr != tt.addr {
+			t.Fatalf("%s: expected the redirect listener on %s but got %s", tt.tlsAddr, tt.addr, addr)
+		}
+		reqCtx := testRedirect(s, MethodGet, "http://example.com/path")
+		if status := reqCtx.Response.StatusCode(); status != tt.status {
+			t.Fatalf("%s: expected status code %d but got %d", tt.tlsAddr, tt.status, status)
+		}
+		if location := string(reqCtx.Response.Header.Peek("Location")); location != tt.location {
+			t.Fatalf("%s: expected the location %s but got %s", tt.tlsAddr, tt.location, location)
+		}
+	}
+
+	// without the config there is no redirect listener
+	if s := newTestRedirectIris(t, nil, ":443"); len(s.Servers) != 1 {
+		t.Fatalf("expected only the main server but got %d servers", len(s.Servers))
+	}
+}
@@ -38,6 +38,7 @@ import (
 	"github.com/kataras/iris/logger"
 	"github.com/kataras/iris/render"
 	"github.com/kataras/iris/server"
+	"github.com/valyala/fasthttp"
 )
 
 type (
@@ -78,16 +79,52 @@ type (
 		// Default is /debug/pprof , which means yourhost.com/debug/pprof
 		ProfilePath string
 
+		// MaxForwardDepth the maximum number of nested ctx.Forward/ctx.Exec calls per request,
+		// used to prevent forward loops
+		// Default is 10
+		MaxForwardDepth int
+
+		// ErrorTemplates the template of the http error pages by their status code, relative to the Render's Directory,
+		// the {code} is replaced by the status code, i.e "errors/{code}" or "errors/{code}.html".
+		// If it doesn't contain {code} then it's the directory of the error templates, "errors" is the same as "errors/{code}".
+		// The default http error handlers render the template of the status code, if it exists, with an HTTPErrorPage
+		// or they write the message as text. The handlers which are registed by the OnError are not affected.
+		// Default is "", the error pages are text
+		ErrorTemplates string
+
+		// HTTPSRedirect if not nil the ListenTLS opens a plain http listener too, which redirects the requests to https
+		// Default is nil
+		HTTPSRedirect *HTTPSRedirectConfig
+
+		// Server the options of the fasthttp servers, the timeouts, the limits and the buffers, i.e ReadTimeout, Concurrency or MaxConnsPerIP,
+		// they are the defaults of the listeners, the non-zero options of a listener's server.Config override them.
+		// Plugins can read the options of each server by its Config, on PreListen
+		// Default is server.DefaultTuning(), the fasthttp's defaults
+		Server server.Tuning
+
 		// Render specify configs for rendering
 		Render *RenderConfig
 	}
 
+	// listener is an extra listener of the Iris, its handler is the router's if nil
+	listener struct {
+		config  server.Config
+		handler fasthttp.RequestHandler
+	}
+
 	// Iris is the container of all, server, router, cache and the sync.Pool
 	Iris struct {
 		*router
-		Server  *server.Server
-		Plugins *PluginContainer
-		render  *render.Render
+		Server *server.Server
+		// Servers the servers of all the listeners, the Server is the first of them, the rest are added by the AddListener or the ListenAll,
+		// all of them serve the same router
+		Servers []*server.Server
+		// listeners the extra listeners which are opened with the Server
+		listeners []listener
+		Plugins   *PluginContainer
+		render    *render.Render
+		// partyRenders the renders of the parties which have their own RenderConfig
+		partyRenders []*partyRender
 		//we want options exported, Options but Options is an http method also, so we make a big change here
 		// and rename the iris.IrisOptions to simple 'iris.IrisConfig' - no iris.Config because of the default func Config()
 		Config *IrisConfig
@@ -109,6 +146,10 @@ func New(configs ...*IrisConfig) *Iris {
 		config.ProfilePath = DefaultProfilePath
 	}
 
+	if config.MaxForwardDepth <= 0 {
+		config.MaxForwardDepth = DefaultMaxForwardDepth
+	}
+
 	// create the Iris
 	s := &Iris{Config: config, Plugins: &PluginContainer{}}
 
@@ -152,11 +193,14 @@ func (s *Iris) DoPreListen(opt server.Config) *server.Server {
 	if !s.router.optimized {
 		s.router.optimize()
 
-		s.Server = server.New(opt)
-		s.Server.SetHandler(s.router.ServeRequest)
-
-		if s.Config.MaxRequestBodySize > 0 {
-			s.Server.MaxRequestBodySize = s.Config.MaxRequestBodySize
+		s.Server = s.newServer(opt)
+		s.Servers = []*server.Server{s.Server}
+		for _, l := range s.listeners {
+			srv := s.newServer(l.config)
+			if l.handler != nil {
+				srv.SetHandler(l.handler)
+			}
+			s.Servers = append(s.Servers, srv)
 		}
 
 		s.Plugins.DoPreListen(s)
@@ -165,6 +209,44 @@ func (s *Iris) DoPreListen(opt server.Config) *server.Server {
 	return s.Server
 }
 
+// newServer returns a new server of the router, it's not started
+func (s *Iris) newServer(opt server.Config) *server.Server {
+	opt.Tuning = opt.Tuning.Merge(s.Config.Server)
+	srv := server.New(opt)
+	srv.SetHandler(s.router.ServeRequest)
+
+	if s.Config.MaxRequestBodySize > 0 {
+		srv.MaxRequestBodySize = s.Config.MaxRequestBodySize
+	}
+	return srv
+}
+
+// AddListener adds listeners which are opened, with the main server, by the Listen, ListenTLS or ListenAll and they serve the same router,
+// i.e http on :80, https on :443 and a unix socket.
+// A listener which is created by the caller is passed by the server.Config's Listener.
+// Call it before the listen, the Servers keep the server of each listener
+func (s *Iris) AddListener(configs ...server.Config) {
+	for _, cfg := range configs {
+		s.listeners = append(s.listeners, listener{config: cfg})
+	}
+}
+
+// openServers opens the servers of all the listeners, if one of them fails then the already opened are closed
+func (s *Iris) openServers() error {
+	for i, srv := range s.Servers {
+		if err := srv.OpenServer(); err != nil {
+			for _, opened := range s.Servers[:i] {
+				opened.CloseServer()
+			}
+			if len(s.Servers) > 1 {
+				return ErrListener.Format(srv.Config.ListeningAddr, err.Error())
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 // DoPostListen sets the render and notice the plugins
 // it's a non-blocking func
 func (s *Iris) DoPostListen() {
@@ -172,6 +254,9 @@ func (s *Iris) DoPostListen() {
 	if s.render == nil {
 		// set the render(er) now
 		s.render = newRender(s.Config.Render)
+		for _, p := range s.partyRenders {
+			p.render = newRender(p.config)
+		}
 		s.Plugins.DoPostListen(s)
 	}
 
@@ -182,7 +267,7 @@ func (s *Iris) DoPostListen() {
 func (s *Iris) openServer(opt server.Config) (err error) {
 	s.DoPreListen(opt)
 
-	if err = s.Server.OpenServer(); err == nil {
+	if err = s.openServers(); err == nil {
 		s.DoPostListen()
 		ch := make(chan os.Signal)
 		<-ch
@@ -226,6 +311,7 @@ func (s *Iris) ListenWithErr(addr string) error {
 // ex: iris.ListenTLS(":8080","yourfile.cert","yourfile.key")
 func (s *Iris) ListenTLS(addr string, certFile, keyFile string) {
 	opt := server.Config{ListeningAddr: addr, CertFile: certFile, KeyFile: keyFile}
+	s.addHTTPSRedirect(addr, certFile)
 	if err := s.openServer(opt); err != nil {
 		panic(err)
 	}
@@ -242,11 +328,66 @@ func (s *Iris) ListenTLS(addr string, certFile, keyFile string) {
 // ex: log.Fatal(iris.ListenTLSWithErr(":8080","yourfile.cert","yourfile.key"))
 func (s *Iris) ListenTLSWithErr(addr string, certFile, keyFile string) error {
 	opt := server.Config{ListeningAddr: addr, CertFile: certFile, KeyFile: keyFile}
+	s.addHTTPSRedirect(addr, certFile)
 	return s.openServer(opt)
 }
 
-// Close is used to close the tcp listener from the server
+// ListenAll starts the servers of all the configs, and of the AddListener, which serve the same router,
+// the first config is the main Server. A config with CertFile and KeyFile is served with TLS,
+// a config with Mode is a unix socket and a config with Listener serves that net.Listener.
+// If one of them fails then the already opened are closed, a retry opens the servers of the first call again, its configs are not added twice.
+//
+// It returns an error you are responsible how to handle this
+// ex: log.Fatal(iris.ListenAll(server.Config{ListeningAddr: ":80"}, server.Config{ListeningAddr: ":443", CertFile: "yourfile.cert", KeyFile: "yourfile.key"}))
+func (s *Iris) ListenAll(configs ...server.Config) error {
+	if len(configs) == 0 {
+		return server.ErrServerOptionsMissing.Return()
+	}
+	// the servers are created once, by the first listen, look DoPreListen
+	if !s.router.optimized {
+		s.AddListener(configs[1:]...)
+	}
+	return s.openServer(configs[0])
+}
+
+// ListenInherited starts the servers of the listeners which are passed to the process by the systemd socket activation (LISTEN_FDS)
+// or by the re-exec of the graceful package, the first listener is the main Server, look server.InheritedListeners.
+// The AddListener listeners are opened too.
+//
+// It returns an error you are responsible how to handle this, ErrNoInheritedListeners if the process has no inherited listeners
+// ex: log.Fatal(iris.ListenInherited())
+func (s *Iris) ListenInherited() error {
+	listeners, err := server.InheritedListeners()
+	if err != nil {
+		return err
+	}
+	if len(listeners) == 0 {
+		return ErrNoInheritedListeners.Return()
+	}
+
+	configs := make([]server.Config, len(listeners))
+	for i, l := range listeners {
+		configs[i] = server.Config{Listener: l}
+	}
+	return s.ListenAll(configs...)
+}
+
+// Close is used to close the tcp listeners of all the servers
 func (s *Iris) Close() error {
 	s.Plugins.DoPreClose(s)
-	return s.Server.CloseServer()
+	if s.render != nil {
+		s.render.Close()
+	}
+	for _, p := range s.partyRenders {
+		if p.render != nil {
+			p.render.Close()
+		}
+	}
+	var err error
+	for _, srv := range s.Servers {
+		if closeErr := srv.CloseServer(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
 }
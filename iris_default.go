@@ -29,6 +29,7 @@ package iris
 
 import (
 	"html/template"
+	"net/http"
 
 	"github.com/kataras/iris/logger"
 	"github.com/kataras/iris/server"
@@ -50,8 +51,13 @@ func DefaultConfig() *IrisConfig {
 		Log:                true,
 		Profile:            false,
 		ProfilePath:        DefaultProfilePath,
+		MaxForwardDepth:    DefaultMaxForwardDepth,
+		ErrorTemplates:     "",
+		HTTPSRedirect:      nil,
+		Server:             server.DefaultTuning(),
 		Render: &RenderConfig{
 			Directory:                 "templates",
+			FileSystem:                nil,
 			Asset:                     nil,
 			AssetNames:                nil,
 			Layout:                    "",
@@ -59,6 +65,9 @@ func DefaultConfig() *IrisConfig {
 			Funcs:                     []template.FuncMap{},
 			Delims:                    Delims{"{{", "}}"},
 			Charset:                   DefaultCharset,
+			Gzip:                      false,
+			CompressEncodings:         []string{"br", "gzip", "deflate"},
+			CompressMinLength:         1024,
 			IndentJSON:                false,
 			IndentXML:                 false,
 			PrefixJSON:                []byte(""),
@@ -67,8 +76,12 @@ func DefaultConfig() *IrisConfig {
 			IsDevelopment:             false,
 			UnEscapeHTML:              false,
 			StreamingJSON:             false,
+			StreamingHTML:             false,
+			StreamingHTMLHeadSize:     4096,
+			MarkdownSanitize:          false,
 			RequirePartials:           false,
 			DisableHTTPErrorRendering: false,
+			Engine:                    nil,
 		}}
 }
 
@@ -118,6 +131,31 @@ func ListenTLSWithErr(addr string, certFile, keyFile string) error {
 	return DefaultIris.ListenTLSWithErr(addr, certFile, keyFile)
 }
 
+// ListenAll starts the servers of all the configs, and of the AddListener, which serve the same router,
+// the first config is the main Server. A config with CertFile and KeyFile is served with TLS,
+// a config with Mode is a unix socket and a config with Listener serves that net.Listener.
+//
+// It returns an error you are responsible how to handle this
+// ex: log.Fatal(iris.ListenAll(server.Config{ListeningAddr: ":80"}, server.Config{ListeningAddr: ":443", CertFile: "yourfile.cert", KeyFile: "yourfile.key"}))
+func ListenAll(configs ...server.Config) error {
+	return DefaultIris.ListenAll(configs...)
+}
+
+// ListenInherited starts the servers of the listeners which are passed to the process by the systemd socket activation (LISTEN_FDS)
+// or by the re-exec of the graceful package, the first listener is the main Server
+//
+// It returns an error you are responsible how to handle this
+// ex: log.Fatal(iris.ListenInherited())
+func ListenInherited() error {
+	return DefaultIris.ListenInherited()
+}
+
+// AddListener adds listeners which are opened, with the main server, by the Listen, ListenTLS or ListenAll and they serve the same router
+// A listener which is created by the caller is passed by the server.Config's Listener
+func AddListener(configs ...server.Config) {
+	DefaultIris.AddListener(configs...)
+}
+
 // Close is used to close the net.Listener of the standalone http server which has already running via .Listen
 func Close() { DefaultIris.Close() }
 
@@ -158,6 +196,17 @@ func UseFunc(handlersFn ...HandlerFunc) {
 	DefaultIris.UseFunc(handlersFn...)
 }
 
+// Done registers Handler(s) which are executed after the main handlers of each route
+// they are always executed, even if a handler didn't called ctx.Next() or called ctx.StopExecution()
+func Done(handlers ...Handler) {
+	DefaultIris.Done(handlers...)
+}
+
+// DoneFunc same as Done but it accepts/receives ...HandlerFunc instead of ...Handler
+func DoneFunc(handlersFn ...HandlerFunc) {
+	DefaultIris.DoneFunc(handlersFn...)
+}
+
 // Get registers a route for the Get http method
 func Get(path string, handlersFn ...HandlerFunc) {
 	DefaultIris.Get(path, handlersFn...)
@@ -208,6 +257,62 @@ func Any(path string, handlersFn ...HandlerFunc) {
 	DefaultIris.Any(path, handlersFn...)
 }
 
+// HandleFuncE same as HandleFunc but it accepts handlers which return an error, func(c *iris.Context) error
+// a non-nil error is passed to the central error handler, look OnHandlerError
+func HandleFuncE(method string, path string, handlersFn ...HandlerFuncE) {
+	DefaultIris.HandleFuncE(method, path, handlersFn...)
+}
+
+// GetE registers a route for the Get http method, the handlers return an error
+func GetE(path string, handlersFn ...HandlerFuncE) {
+	DefaultIris.GetE(path, handlersFn...)
+}
+
+// PostE registers a route for the Post http method, the handlers return an error
+func PostE(path string, handlersFn ...HandlerFuncE) {
+	DefaultIris.PostE(path, handlersFn...)
+}
+
+// PutE registers a route for the Put http method, the handlers return an error
+func PutE(path string, handlersFn ...HandlerFuncE) {
+	DefaultIris.PutE(path, handlersFn...)
+}
+
+// DeleteE registers a route for the Delete http method, the handlers return an error
+func DeleteE(path string, handlersFn ...HandlerFuncE) {
+	DefaultIris.DeleteE(path, handlersFn...)
+}
+
+// ConnectE registers a route for the Connect http method, the handlers return an error
+func ConnectE(path string, handlersFn ...HandlerFuncE) {
+	DefaultIris.ConnectE(path, handlersFn...)
+}
+
+// HeadE registers a route for the Head http method, the handlers return an error
+func HeadE(path string, handlersFn ...HandlerFuncE) {
+	DefaultIris.HeadE(path, handlersFn...)
+}
+
+// OptionsE registers a route for the Options http method, the handlers return an error
+func OptionsE(path string, handlersFn ...HandlerFuncE) {
+	DefaultIris.OptionsE(path, handlersFn...)
+}
+
+// PatchE registers a route for the Patch http method, the handlers return an error
+func PatchE(path string, handlersFn ...HandlerFuncE) {
+	DefaultIris.PatchE(path, handlersFn...)
+}
+
+// TraceE registers a route for the Trace http method, the handlers return an error
+func TraceE(path string, handlersFn ...HandlerFuncE) {
+	DefaultIris.TraceE(path, handlersFn...)
+}
+
+// AnyE registers a route for ALL of the http methods, the handlers return an error
+func AnyE(path string, handlersFn ...HandlerFuncE) {
+	DefaultIris.AnyE(path, handlersFn...)
+}
+
 // Static registers a route which serves a system directory
 // this doesn't generates an index page which list all files
 // no compression is used also, for these features look at StaticFS func
@@ -237,6 +342,48 @@ func StaticFS(relative string, systemPath string, stripSlashes int) {
 	DefaultIris.StaticFS(relative, systemPath, stripSlashes)
 }
 
+// StaticMarkdown registers a route which serves the .md files of a system directory as html pages
+// accepts three parameters
+// first parameter is the request url path (string)
+// second parameter is the system directory (string)
+// third parameter is the template (string) which renders the pages, it receives a MarkdownPage, i.e {{ .Front.title }} and {{ .Content }}
+// the "/docs/guides/install" is served by the systemPath/guides/install.md and the "/docs/" by the systemPath/index.md
+func StaticMarkdown(relative string, systemPath string, templateName string) {
+	DefaultIris.StaticMarkdown(relative, systemPath, templateName)
+}
+
+// StaticFileSystem registers a route which serves the files of a http.FileSystem, a directory is served by its index.html, the byte ranges are supported
+// accepts two parameters
+// first parameter is the request url path (string)
+// second parameter is the file system (http.FileSystem), i.e utils.NewMapFS, utils.NewZipFS or utils.NewAssetFS, so the files can be embedded into the binary
+func StaticFileSystem(relative string, fs http.FileSystem) {
+	DefaultIris.StaticFileSystem(relative, fs)
+}
+
+// StaticAssets registers a route which serves the files of the assets at their fingerprinted urls, with a far-future Cache-Control,
+// and registers the "asset" template func, i.e {{ asset "css/app.css" }}
+// accepts two parameters
+// first parameter is the request url path (string)
+// second parameter is the assets (*Assets), look NewAssets and NewAssetsFromManifest
+func StaticAssets(relative string, assets *Assets) {
+	DefaultIris.StaticAssets(relative, assets)
+}
+
+// SPA registers a route which serves a single-page application, the existing files are served
+// and every other GET path under the relative path, which has no extension, is served by the index.html
+// accepts three parameters
+// first parameter is the request url path (string)
+// second parameter is the system directory (string), it's ignored if the options' FileSystem is not nil
+// third parameter is optional, the options (SPAOptions)
+func SPA(relative string, systemPath string, options ...SPAOptions) {
+	DefaultIris.SPA(relative, systemPath, options...)
+}
+
+// StaticMarkdownFileSystem same as StaticMarkdown but the .md files are served from a http.FileSystem
+func StaticMarkdownFileSystem(relative string, fs http.FileSystem, templateName string) {
+	DefaultIris.StaticMarkdownFileSystem(relative, fs, templateName)
+}
+
 // StaticWeb same as Static but if index.html exists and request uri is '/' then display the index.html's contents
 // accepts three parameters
 // first parameter is the request url path (string)
@@ -249,6 +396,13 @@ func StaticWeb(relative string, systemPath string, stripSlashes int) {
 	DefaultIris.StaticWeb(relative, systemPath, stripSlashes)
 }
 
+// Batch registers a POST route which accepts a JSON array of sub-requests ({method, path, headers, body})
+// each one of them is executed through the router, in-process, with its own Context
+// and the route responds with a JSON array of their responses ({status, headers, body}), in the same order
+func Batch(path string, config ...BatchConfig) {
+	DefaultIris.Batch(path, config...)
+}
+
 // OnError Registers a handler for a specific http error status
 func OnError(httpStatus int, handler HandlerFunc) {
 	DefaultIris.OnError(httpStatus, handler)
@@ -259,6 +413,24 @@ func EmitError(httpStatus int, ctx *Context) {
 	DefaultIris.EmitError(httpStatus, ctx)
 }
 
+// OnHandlerError sets the central error handler, which receives the errors returned by the HandlerFuncE handlers
+// by default the errors are logged through the Iris.Logger and the http error of their status code is emitted
+func OnHandlerError(handler ErrorHandler) {
+	DefaultIris.OnHandlerError(handler)
+}
+
+// MapError maps an error value to a http status code,
+// used by the central error handler when a handler returns this error
+func MapError(err error, httpStatus int) {
+	DefaultIris.MapError(err, httpStatus)
+}
+
+// MapErrorType maps the type of an error to a http status code,
+// used by the central error handler when a handler returns an error of the same type
+func MapErrorType(err error, httpStatus int) {
+	DefaultIris.MapErrorType(err, httpStatus)
+}
+
 // OnNotFound sets the handler for http status 404,
 // default is a response with text: 'Not Found' and status: 404
 func OnNotFound(handlerFunc HandlerFunc) {
@@ -304,6 +476,11 @@ func SetMaxRequestBodySize(size int) {
 	DefaultIris.SetMaxRequestBodySize(size)
 }
 
+// ProblemErrors makes all the default http errors problem details objects (RFC 7807), use the iris.Party("/api").ProblemErrors() for a party
+func ProblemErrors() {
+	DefaultIris.ProblemErrors()
+}
+
 // SetRenderConfig sets the Config.Render, can be setted before server's listen, not after.
 func SetRenderConfig(renderCfg *RenderConfig) {
 	DefaultIris.SetRenderConfig(renderCfg)
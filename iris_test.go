@@ -0,0 +1,200 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/server"
+	"github.com/valyala/fasthttp"
+)
+
+// newTestIris returns a new Iris with the logger disabled
+func newTestIris() *Iris {
+	config := DefaultConfig()
+	config.Log = false
+	return New(config)
+}
+
+// testServe prepares the station to serve the requests of the testRequest, without listening
+func testServe(s *Iris) {
+	s.DoPreListen(server.Config{})
+	s.DoPostListen()
+}
+
+// testRequest executes a request through the router, in-memory, and returns the fasthttp.RequestCtx which holds the response.
+// The headers are pairs of key and value
+func testRequest(s *Iris, method string, uri string, body string, headers ...string) *fasthttp.RequestCtx {
+	var req fasthttp.Request
+	req.Header.SetMethod(method)
+	req.SetRequestURI(uri)
+	req.SetBodyString(body)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	reqCtx := new(fasthttp.RequestCtx)
+	reqCtx.Init(&req, &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 52000}, log.New(ioutil.Discard, "", 0))
+	s.router.ServeRequest(reqCtx)
+	return reqCtx
+}
+
+// expectResponse fails the test if the status code or the body of the response are not the expected
+func expectResponse(t *testing.T, reqCtx *fasthttp.RequestCtx, statusCode int, body string) {
+	t.Helper()
+	if got := reqCtx.Response.StatusCode(); got != statusCode {
+		t.Fatalf("%s %s: expected status code %d but got %d, body: %q", reqCtx.Method(), reqCtx.RequestURI(), statusCode, got, reqCtx.Response.Body())
+	}
+	if got := string(reqCtx.Response.Body()); got != body {
+		t.Fatalf("%s %s: expected body %q but got %q", reqCtx.Method(), reqCtx.RequestURI(), body, got)
+	}
+}
+
+// httpGet sends a GET request with the client and returns the body of the response
+func httpGet(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	return string(body)
+}
+
+func TestListenAll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-listen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "iris.sock")
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := newTestIris()
+	s.Get("/", func(ctx *Context) {
+		ctx.Text(StatusOK, "hello")
+	})
+	s.AddListener(server.Config{Listener: l}, server.Config{ListeningAddr: socket, Mode: 0666})
+	s.DoPreListen(server.Config{ListeningAddr: "127.0.0.1:0"})
+	if err := s.openServers(); err != nil {
+		t.Fatal(err)
+	}
+	s.DoPostListen()
+
+	if len(s.Servers) != 3 || s.Servers[0] != s.Server {
+		t.Fatalf("expected the main server and the two listeners but got %d servers", len(s.Servers))
+	}
+
+	// the tcp listeners, the one which is created by the iris and the one which is created by the caller
+	for _, srv := range s.Servers[0:2] {
+		if body := httpGet(t, http.DefaultClient, "http://"+srv.Listener().Addr().String()+"/"); body != "hello" {
+			t.Fatalf("expected the body hello but got %q", body)
+		}
+	}
+
+	unixClient := &http.Client{Transport: &http.Transport{Dial: func(string, string) (net.Conn, error) {
+		return net.Dial("unix", socket)
+	}}}
+	if body := httpGet(t, unixClient, "http://unix/"); body != "hello" {
+		t.Fatalf("expected the body hello from the unix socket but got %q", body)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, srv := range s.Servers {
+		if srv.IsListening() {
+			t.Fatalf("expected the server of %s to be closed", srv.Config.ListeningAddr)
+		}
+	}
+}
+
+func TestListenAllFailure(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+
+	s := newTestIris()
+	err = s.ListenAll(server.Config{ListeningAddr: "127.0.0.1:0"}, server.Config{ListeningAddr: busy.Addr().String()})
+	if err == nil || !strings.Contains(err.Error(), busy.Addr().String()) {
+		t.Fatalf("expected the error of the busy listener but got %v", err)
+	}
+	// the already opened listeners are closed
+	if s.Servers[0].IsListening() {
+		t.Fatal("expected the main server to be closed")
+	}
+
+	// a retry doesn't add the listeners again
+	if err = s.ListenAll(server.Config{ListeningAddr: "127.0.0.1:0"}, server.Config{ListeningAddr: busy.Addr().String()}); err == nil {
+		t.Fatal("expected the error of the busy listener on the retry")
+	}
+	if len(s.listeners) != 1 || len(s.Servers) != 2 {
+		t.Fatalf("expected the main server and one listener but got %d servers and %d listeners", len(s.Servers), len(s.listeners))
+	}
+
+	if err := newTestIris().ListenAll(); err == nil {
+		t.Fatal("expected an error without configs")
+	}
+}
+
+func TestServerTuning(t *testing.T) {
+	s := newTestIris()
+	s.Config.Server = server.Tuning{ReadTimeout: 5 * time.Second, Concurrency: 10}
+	s.AddListener(server.Config{ListeningAddr: "127.0.0.1:0"})
+	s.DoPreListen(server.Config{ListeningAddr: "127.0.0.1:0", Tuning: server.Tuning{Concurrency: 20, MaxConnsPerIP: 2}})
+
+	// the non-zero options of a listener override the Config.Server
+	main := s.Server.Config.Tuning
+	if main.Concurrency != 20 || main.MaxConnsPerIP != 2 || main.ReadTimeout != 5*time.Second {
+		t.Fatalf("unexpected tuning of the main server %+v", main)
+	}
+	if s.Server.Server.Concurrency != 20 || s.Server.Server.ReadTimeout != 5*time.Second || s.Server.Server.Name != server.DefaultServerName {
+		t.Fatal("expected the tuning to be applied to the fasthttp server of the main server")
+	}
+	if added := s.Servers[1].Config.Tuning; added.Concurrency != 10 || added.ReadTimeout != 5*time.Second {
+		t.Fatalf("unexpected tuning of the added listener %+v", added)
+	}
+}
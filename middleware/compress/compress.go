@@ -0,0 +1,143 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package compress
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/render"
+)
+
+// DefaultContentTypes the content types which are compressed by default,
+// an entry which ends with "/*" matches all the subtypes of the type.
+var DefaultContentTypes = []string{
+	"text/*",
+	"application/json",
+	"application/x-ndjson",
+	"application/javascript",
+	"application/x-javascript",
+	"application/xml",
+	"application/xhtml+xml",
+	"application/rss+xml",
+	"application/atom+xml",
+	"image/svg+xml",
+}
+
+// Options is a struct for specifying configuration options for the compress middleware.
+type Options struct {
+	// ContentTypes the allow-list of the content types which will be compressed,
+	// an entry which ends with "/*" matches all the subtypes of the type. Default is DefaultContentTypes
+	ContentTypes []string
+	// Encodings the encodings which are offered to the clients, in order of preference.
+	// Default is ["br", "gzip", "deflate"]
+	Encodings []string
+	// MinLength bodies smaller than this length (in bytes) are not compressed, streaming bodies are always compressed. Default is 1024
+	MinLength int
+}
+
+type compress struct {
+	opt Options
+}
+
+// New returns the compress middleware as iris.Handler,
+// it compresses any response of the next handlers, including the body streams of the .Stream, the .SetBodyStream(Writer)
+// and the render's JSONStream, CSV and streaming HTML, a body stream which is set on the ctx.RequestCtx directly is sent as it is,
+// with the encoding which is negotiated by the client's Accept-Encoding header.
+// Responses which have a Content-Encoding already are never compressed twice.
+// receives an optional Options, the defaults are used if no options given
+func New(options ...Options) iris.Handler {
+	c := &compress{}
+	if len(options) > 0 {
+		c.opt = options[0]
+	}
+	if len(c.opt.ContentTypes) == 0 {
+		c.opt.ContentTypes = DefaultContentTypes
+	}
+	if len(c.opt.Encodings) == 0 {
+		c.opt.Encodings = render.DefaultCompressEncodings
+	}
+	if c.opt.MinLength <= 0 {
+		c.opt.MinLength = render.DefaultCompressMinLength
+	}
+	return c
+}
+
+func (c *compress) Serve(ctx *iris.Context) {
+	ctx.WrapStream(c.wrapStream(ctx))
+	ctx.Next()
+
+	if ctx.Response.IsBodyStream() || !c.compressible(ctx) {
+		// the body streams are compressed by the wrapper
+		return
+	}
+	render.CompressResponse(ctx.RequestCtx, c.opt.Encodings, c.opt.MinLength)
+}
+
+// compressible reports whether the content type of the response is allowed and it's not encoded already.
+func (c *compress) compressible(ctx *iris.Context) bool {
+	if len(ctx.Response.Header.Peek(render.ContentEncoding)) > 0 {
+		return false
+	}
+	contentType := string(ctx.Response.Header.ContentType())
+	if idx := strings.IndexByte(contentType, ';'); idx >= 0 {
+		contentType = contentType[0:idx]
+	}
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+
+	for _, allowed := range c.opt.ContentTypes {
+		if strings.HasSuffix(allowed, "/*") {
+			if strings.HasPrefix(contentType, allowed[0:len(allowed)-1]) {
+				return true
+			}
+		} else if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// wrapStream returns the wrapper which compresses the body streams,
+// the encoding is decided when the body stream is set, so the headers should be set before that.
+func (c *compress) wrapStream(ctx *iris.Context) iris.StreamWrapper {
+	return func(cb func(*bufio.Writer)) func(*bufio.Writer) {
+		if !c.compressible(ctx) {
+			return cb
+		}
+		render.AddVary(&ctx.Response.Header, render.AcceptEncoding)
+		encoding := render.NegotiateEncoding(string(ctx.Request.Header.Peek(render.AcceptEncoding)), c.opt.Encodings)
+		if encoding == "" {
+			return cb
+		}
+		ctx.Response.Header.Set(render.ContentEncoding, encoding)
+
+		// each flush of the handler's writer is flushed to the client too, so the streaming keeps working
+		return render.CompressStreamWriter(encoding, cb)
+	}
+}
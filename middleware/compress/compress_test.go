@@ -0,0 +1,181 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package compress
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/render"
+	"github.com/kataras/iris/server"
+	"github.com/klauspost/compress/gzip"
+	"github.com/valyala/fasthttp"
+)
+
+var large = strings.Repeat("compress me ", 200)
+
+type testRow struct {
+	Name string `csv:"name"`
+}
+
+func newTestIris() *iris.Iris {
+	config := iris.DefaultConfig()
+	config.Log = false
+	config.Render.Directory = "templates"
+	config.Render.Asset = func(string) ([]byte, error) { return []byte(`{{.}}`), nil }
+	config.Render.AssetNames = func() []string { return []string{"templates/index.html"} }
+	config.Render.StreamingHTML = true
+	config.Render.StreamingHTMLHeadSize = 64
+	s := iris.New(config)
+	s.Use(New())
+
+	s.Get("/text", func(ctx *iris.Context) {
+		ctx.Text(iris.StatusOK, ctx.URLParam("body"))
+	})
+	s.Get("/image", func(ctx *iris.Context) {
+		ctx.SetContentType([]string{"image/png"})
+		ctx.Stream(func(w *bufio.Writer) {
+			w.WriteString(large)
+		})
+	})
+	s.Get("/stream", func(ctx *iris.Context) {
+		ctx.SetContentType([]string{"text/plain"})
+		ctx.Stream(func(w *bufio.Writer) {
+			w.WriteString(large)
+		})
+	})
+	s.Get("/reader", func(ctx *iris.Context) {
+		ctx.SetContentType([]string{"text/plain"})
+		ctx.SetBodyStream(strings.NewReader(large), len(large))
+	})
+	s.Get("/jsonstream", func(ctx *iris.Context) {
+		ch := make(chan interface{}, 2)
+		ch <- "a"
+		ch <- "b"
+		close(ch)
+		ctx.JSONStream(iris.StatusOK, ch)
+	})
+	s.Get("/csv", func(ctx *iris.Context) {
+		ctx.CSV(iris.StatusOK, []testRow{{"a"}, {"b"}})
+	})
+	s.Get("/html", func(ctx *iris.Context) {
+		ctx.HTML(iris.StatusOK, "index", large)
+	})
+	s.DoPreListen(server.Config{})
+	s.DoPostListen()
+	return s
+}
+
+// do serves a request in-memory and returns the response's content encoding and its decompressed body
+func do(t *testing.T, s *iris.Iris, uri string, acceptEncoding string) (string, string, *fasthttp.RequestCtx) {
+	t.Helper()
+	var req fasthttp.Request
+	req.SetRequestURI(uri)
+	if acceptEncoding != "" {
+		req.Header.Set(render.AcceptEncoding, acceptEncoding)
+	}
+	reqCtx := new(fasthttp.RequestCtx)
+	reqCtx.Init(&req, &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 52000}, nil)
+	s.Server.Handler()(reqCtx)
+
+	var body bytes.Buffer
+	if err := reqCtx.Response.BodyWriteTo(&body); err != nil {
+		t.Fatalf("%s: %v", uri, err)
+	}
+	encoding := string(reqCtx.Response.Header.Peek(render.ContentEncoding))
+	if encoding != render.EncodingGzip {
+		return encoding, body.String(), reqCtx
+	}
+	gr, err := gzip.NewReader(&body)
+	if err != nil {
+		t.Fatalf("%s: %v", uri, err)
+	}
+	b, err := ioutil.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("%s: %v", uri, err)
+	}
+	return encoding, string(b), reqCtx
+}
+
+func TestCompress(t *testing.T) {
+	s := newTestIris()
+
+	tests := []struct {
+		uri      string
+		encoding string
+		body     string
+	}{
+		{"/text?body=" + strings.Replace(large, " ", "+", -1), render.EncodingGzip, large},
+		{"/text?body=small", "", "small"},
+		{"/image", "", large},
+		{"/stream", render.EncodingGzip, large},
+		{"/reader", render.EncodingGzip, large},
+		{"/jsonstream", render.EncodingGzip, "\"a\"\n\"b\"\n"},
+		{"/csv", render.EncodingGzip, "name\na\nb\n"},
+		{"/html", render.EncodingGzip, large},
+	}
+
+	for _, tt := range tests {
+		encoding, body, reqCtx := do(t, s, tt.uri, "gzip")
+		if encoding != tt.encoding {
+			t.Fatalf("%s: expected the encoding %q but got %q", tt.uri, tt.encoding, encoding)
+		}
+		if body != tt.body {
+			t.Fatalf("%s: unexpected body %q", tt.uri, body)
+		}
+		if tt.encoding != "" {
+			if vary := string(reqCtx.Response.Header.Peek(render.Vary)); vary != render.AcceptEncoding {
+				t.Fatalf("%s: expected the Vary header but got %q", tt.uri, vary)
+			}
+		}
+	}
+}
+
+func TestCompressNotAccepted(t *testing.T) {
+	s := newTestIris()
+	for _, uri := range []string{"/stream", "/reader", "/jsonstream", "/csv", "/html"} {
+		encoding, body, _ := do(t, s, uri, "")
+		if encoding != "" {
+			t.Fatalf("%s: unexpected encoding %q", uri, encoding)
+		}
+		if body == "" {
+			t.Fatalf("%s: expected a body", uri)
+		}
+	}
+
+	// the client accepts only the encodings which are not offered
+	encoding, body, _ := do(t, s, "/stream", "zstd")
+	if encoding != "" || body != large {
+		t.Fatalf("unexpected encoding %q", encoding)
+	}
+}
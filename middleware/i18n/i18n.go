@@ -28,10 +28,13 @@
 package i18n
 
 import (
+	"net/http"
 	"strings"
 
 	"github.com/Unknwon/i18n"
 	"github.com/kataras/iris"
+	"github.com/kataras/iris/render"
+	"github.com/kataras/iris/utils"
 )
 
 // AcceptLanguage is the Header key "Accept-Language"
@@ -52,6 +55,10 @@ type Options struct {
 	// Example of key is: 'en-US'
 	// Example of value is: './locales/en-US.ini'
 	Languages map[string]string
+	// FileSystem if not nil the Languages' file locations are the paths of the files inside it, i.e utils.NewMapFS or utils.NewZipFS
+	//
+	// Checked: Configuration state, not at runtime
+	FileSystem http.FileSystem
 }
 
 type i18nMiddleware struct {
@@ -86,6 +93,8 @@ func (i *i18nMiddleware) Serve(ctx *iris.Context) {
 	locale := i18n.Locale{language}
 	ctx.Set("language", language)
 	ctx.Set("translate", locale.Tr)
+	// the templates can use it too, i.e {{ translate "hello" }}
+	ctx.SetTemplateFunc("translate", locale.Tr)
 	ctx.Next()
 }
 
@@ -103,7 +112,15 @@ func I18nHandler(_options ...Options) *i18nMiddleware {
 		if !strings.HasSuffix(v, ".ini") {
 			v += ".ini"
 		}
-		err := i18n.SetMessage(k, v)
+		var localeFile interface{} = v
+		if i.options.FileSystem != nil {
+			data, err := utils.ReadFile(i.options.FileSystem, v)
+			if err != nil {
+				panic("Iris i18n Middleware: Failed to read locale file" + k + " Error:" + err.Error())
+			}
+			localeFile = data
+		}
+		err := i18n.SetMessage(k, localeFile)
 		if err != nil && err != i18n.ErrLangAlreadyExist {
 			panic("Iris i18n Middleware: Failed to set locale file" + k + " Error:" + err.Error())
 		}
@@ -117,6 +134,8 @@ func I18nHandler(_options ...Options) *i18nMiddleware {
 	}
 
 	i18n.SetDefaultLang(i.options.Default)
+	// the templates are compiled with the default language's translator, the requests use their own
+	render.DeclareRequestFunc("translate", i18n.Locale{Lang: i.options.Default}.Tr)
 	return i
 }
 
@@ -37,6 +37,9 @@ import (
 // Pongo2Middleware the middleware for pongo2
 // Conflicts with the render if pongo2 directory is './templates' we have issue see -> Serve ->p.once
 // so please just change the iris.Config().Render.Directory = "tosomethingdifferent"
+//
+// Deprecated: use the pongo2 template engine of the render package instead, iris.Config().Render.Engine = render.NewPongo2Engine()
+// then the ctx.Render/ctx.HTML work with the pongo2 templates
 
 type Pongo2Middleware struct {
 	templateDir string
@@ -28,6 +28,7 @@
 package recovery
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"time"
@@ -44,6 +45,8 @@ func (r recovery) Serve(ctx *iris.Context) {
 	defer func() {
 		if err := recover(); err != nil {
 			r.out.Write([]byte("[" + time.Now().String() + "]Recovery from panic \n"))
+			// the error templates show it on development, look iris.IrisConfig.ErrorTemplates
+			iris.ErrorKey.Set(ctx, fmt.Errorf("panic: %v", err))
 			//ctx.Panic just sends  http status 500 by default, but you can change it by: iris.OnPanic(func( c *iris.Context){})
 			ctx.Panic()
 		}
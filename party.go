@@ -28,11 +28,16 @@
 package iris
 
 import (
+	"html/template"
+	"io"
+	"net/http"
+	"os"
 	"path"
 	"reflect"
 	"strconv"
 	"strings"
 
+	"github.com/kataras/iris/render"
 	"github.com/kataras/iris/utils"
 	"github.com/valyala/fasthttp"
 )
@@ -53,8 +58,26 @@ type (
 		Patch(string, ...HandlerFunc)
 		Trace(string, ...HandlerFunc)
 		Any(string, ...HandlerFunc)
+		// HandleFuncE, GetE, PostE... register the handlers which return an error, look HandlerFuncE
+		HandleFuncE(string, string, ...HandlerFuncE)
+		GetE(string, ...HandlerFuncE)
+		PostE(string, ...HandlerFuncE)
+		PutE(string, ...HandlerFuncE)
+		DeleteE(string, ...HandlerFuncE)
+		ConnectE(string, ...HandlerFuncE)
+		HeadE(string, ...HandlerFuncE)
+		OptionsE(string, ...HandlerFuncE)
+		PatchE(string, ...HandlerFuncE)
+		TraceE(string, ...HandlerFuncE)
+		AnyE(string, ...HandlerFuncE)
 		Use(...Handler)
 		UseFunc(...HandlerFunc)
+		Done(...Handler)
+		DoneFunc(...HandlerFunc)
+		// SetRenderConfig sets the templates, layout and the rest render options of the party's routes
+		SetRenderConfig(*RenderConfig)
+		// ProblemErrors makes the default http errors of the party's paths problem details objects (RFC 7807)
+		ProblemErrors()
 		// Static serves a directory
 		// accepts three parameters
 		// first parameter is the request url path (string)
@@ -65,6 +88,18 @@ type (
 		// * stripSlashes = 2, original path: "/foo/bar", result: ""
 		Static(string, string, int)
 		StaticFS(string, string, int)
+		// StaticFileSystem serves the files of a http.FileSystem, i.e the utils.NewMapFS or the utils.NewZipFS
+		StaticFileSystem(string, http.FileSystem)
+		// StaticAssets serves the files of an Assets at fingerprinted urls and registers the "asset" template func
+		StaticAssets(string, *Assets)
+		// SPA serves a single-page application, the unknown paths are served by its index.html
+		SPA(string, string, ...SPAOptions)
+		// StaticMarkdown serves the .md files of a directory as html pages, rendered by a template
+		StaticMarkdown(string, string, string)
+		// StaticMarkdownFileSystem same as StaticMarkdown but the .md files are served from a http.FileSystem
+		StaticMarkdownFileSystem(string, http.FileSystem, string)
+		// Batch registers a POST route which executes a JSON array of sub-requests through the router
+		Batch(string, ...BatchConfig)
 		Party(string, ...HandlerFunc) IParty // Each party can have a party too
 		IsRoot() bool
 	}
@@ -74,7 +109,16 @@ type (
 		relativePath string
 		station      *Iris // this station is where the party is happening, this station's Garden is the same for all Parties per Station & Router instance
 		middleware   Middleware
-		root         bool
+		done         Middleware
+		// render the party's own render, look SetRenderConfig
+		render *partyRender
+		root   bool
+	}
+
+	// doneMiddleware is the first handler of the routes which have done handlers,
+	// it passes the done handlers to the Context, the router executes them after the main handlers
+	doneMiddleware struct {
+		handlers Middleware
 	}
 )
 
@@ -88,13 +132,24 @@ func (p *GardenParty) IsRoot() bool {
 // Handle registers a route to the server's router
 func (p *GardenParty) Handle(method string, registedPath string, handlers ...Handler) {
 	path := fixPath(absPath(p.relativePath, registedPath))
-	middleware := JoinMiddleware(p.middleware, handlers)
-	route := NewRoute(method, path, middleware)
+	route := NewRoute(method, path, p.routeMiddleware(handlers))
 	p.station.Plugins.DoPreHandle(route)
 	p.station.addRoute(route)
 	p.station.Plugins.DoPostHandle(route)
 }
 
+// routeMiddleware returns the handlers of a route of this party, after the party's middleware, done and render handlers
+func (p *GardenParty) routeMiddleware(handlers Middleware) Middleware {
+	middleware := JoinMiddleware(p.middleware, handlers)
+	if len(p.done) > 0 {
+		middleware = JoinMiddleware(Middleware{doneMiddleware{handlers: p.done}}, middleware)
+	}
+	if p.render != nil {
+		middleware = JoinMiddleware(Middleware{p.render}, middleware)
+	}
+	return middleware
+}
+
 // HandleFunc registers and returns a route with a method string, path string and a handler
 // registedPath is the relative url path
 // handler is the iris.Handler which you can pass anything you want via iris.ToHandlerFunc(func(res,req){})... or just use func(c *iris.Context)
@@ -102,6 +157,12 @@ func (p *GardenParty) HandleFunc(method string, registedPath string, handlersFn
 	p.Handle(method, registedPath, ConvertToHandlers(handlersFn)...)
 }
 
+// HandleFuncE same as HandleFunc but it accepts handlers which return an error, func(c *iris.Context) error
+// a non-nil error is passed to the central error handler, look OnHandlerError
+func (p *GardenParty) HandleFuncE(method string, registedPath string, handlersFn ...HandlerFuncE) {
+	p.Handle(method, registedPath, ConvertToHandlersE(handlersFn)...)
+}
+
 // HandleAnnotated registers a route handler using a Struct implements iris.Handler (as anonymous property)
 // which it's metadata has the form of
 // `method:"path"` and returns the route and an error if any occurs
@@ -211,6 +272,58 @@ func (p *GardenParty) Any(path string, handlersFn ...HandlerFunc) {
 
 }
 
+// GetE registers a route for the Get http method, the handlers return an error
+func (p *GardenParty) GetE(path string, handlersFn ...HandlerFuncE) {
+	p.HandleFuncE(MethodGet, path, handlersFn...)
+}
+
+// PostE registers a route for the Post http method, the handlers return an error
+func (p *GardenParty) PostE(path string, handlersFn ...HandlerFuncE) {
+	p.HandleFuncE(MethodPost, path, handlersFn...)
+}
+
+// PutE registers a route for the Put http method, the handlers return an error
+func (p *GardenParty) PutE(path string, handlersFn ...HandlerFuncE) {
+	p.HandleFuncE(MethodPut, path, handlersFn...)
+}
+
+// DeleteE registers a route for the Delete http method, the handlers return an error
+func (p *GardenParty) DeleteE(path string, handlersFn ...HandlerFuncE) {
+	p.HandleFuncE(MethodDelete, path, handlersFn...)
+}
+
+// ConnectE registers a route for the Connect http method, the handlers return an error
+func (p *GardenParty) ConnectE(path string, handlersFn ...HandlerFuncE) {
+	p.HandleFuncE(MethodConnect, path, handlersFn...)
+}
+
+// HeadE registers a route for the Head http method, the handlers return an error
+func (p *GardenParty) HeadE(path string, handlersFn ...HandlerFuncE) {
+	p.HandleFuncE(MethodHead, path, handlersFn...)
+}
+
+// OptionsE registers a route for the Options http method, the handlers return an error
+func (p *GardenParty) OptionsE(path string, handlersFn ...HandlerFuncE) {
+	p.HandleFuncE(MethodOptions, path, handlersFn...)
+}
+
+// PatchE registers a route for the Patch http method, the handlers return an error
+func (p *GardenParty) PatchE(path string, handlersFn ...HandlerFuncE) {
+	p.HandleFuncE(MethodPatch, path, handlersFn...)
+}
+
+// TraceE registers a route for the Trace http method, the handlers return an error
+func (p *GardenParty) TraceE(path string, handlersFn ...HandlerFuncE) {
+	p.HandleFuncE(MethodTrace, path, handlersFn...)
+}
+
+// AnyE registers a route for ALL of the http methods, the handlers return an error
+func (p *GardenParty) AnyE(path string, handlersFn ...HandlerFuncE) {
+	for _, k := range AllMethods {
+		p.HandleFuncE(k, path, handlersFn...)
+	}
+}
+
 // Use registers a Handler middleware
 func (p *GardenParty) Use(handlers ...Handler) {
 	p.middleware = append(p.middleware, handlers...)
@@ -221,6 +334,47 @@ func (p *GardenParty) UseFunc(handlersFn ...HandlerFunc) {
 	p.Use(ConvertToHandlers(handlersFn)...)
 }
 
+// Done registers Handler(s) which are executed after the main handlers of each route of this party
+// they are always executed, even if a handler didn't called ctx.Next() or called ctx.StopExecution(),
+// so they are useful for audit logging and metrics.
+// The done handlers of the station (the root party) are executed for the requests which match no route too, i.e the 404 and the path correction's redirects.
+// A forwarded request executes the done handlers once, the ones of the route which it's forwarded to.
+//
+// Note: the done handlers are executed one after the other, there is no need to call ctx.Next() inside them
+func (p *GardenParty) Done(handlers ...Handler) {
+	p.done = append(p.done, handlers...)
+}
+
+// DoneFunc same as Done but it accepts/receives ...HandlerFunc instead of ...Handler
+func (p *GardenParty) DoneFunc(handlersFn ...HandlerFunc) {
+	p.Done(ConvertToHandlers(handlersFn)...)
+}
+
+// Serve sets the done handlers to the Context and continues to the next handler
+func (d doneMiddleware) Serve(ctx *Context) {
+	ctx.done = d.handlers
+	ctx.Next()
+}
+
+// SetRenderConfig gives the party its own RenderConfig, so its routes can have different template directory, layout or funcs
+// than the rest, i.e an admin panel. The ctx.Render and the rest render methods of the party's routes use this config.
+// The fields are not inherited from the station's Config.Render, start from a copy of it if needed.
+// The child parties use it too.
+//
+// Note: it affects the routes which are registed after this call, the templates are loaded when the server starts
+func (p *GardenParty) SetRenderConfig(renderCfg *RenderConfig) {
+	p.render = &partyRender{config: renderCfg}
+	p.station.partyRenders = append(p.station.partyRenders, p.render)
+}
+
+// ProblemErrors makes the default http errors (404, 500 and the rest which are not registed by the OnError) of the party's paths
+// problem details objects (RFC 7807), as json or xml by the Accept header, instead of text or error templates, useful for the api parties.
+// It covers the paths under the party's path which are not registed too, i.e the 404 of the /api/missing.
+// If the handled error is a Problem it's sent as it is.
+func (p *GardenParty) ProblemErrors() {
+	p.station.ProblemErrorsFor(p.relativePath)
+}
+
 // StaticHandlerFunc returns a HandlerFunc to serve static system directory
 func StaticHandlerFunc(systemPath string, stripSlashes int, compress bool, generateIndexPages bool) HandlerFunc {
 	fs := &fasthttp.FS{
@@ -234,13 +388,14 @@ func StaticHandlerFunc(systemPath string, stripSlashes int, compress bool, gener
 		Compress: compress,
 	}
 
-	// Create request handler for serving static files.
-	h := fs.NewRequestHandler()
-
+	// the path rewrite must be set before the request handler is created, it's copied then
 	if stripSlashes > 0 {
 		fs.PathRewrite = fasthttp.NewPathSlashesStripper(stripSlashes)
 	}
 
+	// Create request handler for serving static files.
+	h := fs.NewRequestHandler()
+
 	return func(ctx *Context) {
 		h(ctx.RequestCtx)
 	}
@@ -298,21 +453,210 @@ func (p *GardenParty) StaticWeb(relative string, systemPath string, stripSlashes
 		relative += "/"
 	}
 
-	serveHandler := StaticHandlerFunc(systemPath, 1, false, false)
+	serveHandler := StaticHandlerFunc(systemPath, stripSlashes, false, false)
 	hasIndex := utils.Exists(systemPath + utils.PathSeparator + "index.html")
 	p.Get(relative+"*filepath", func(ctx *Context) {
 		if len(ctx.Param("filepath")) < 2 && hasIndex {
-			ctx.Request.SetRequestURI("index.html")
+			// the index.html of the requested directory, so the stripSlashes are applied to it as well
+			ctx.Request.SetRequestURI(path.Join(string(ctx.Path()), "index.html"))
 		}
 		ctx.Next()
 
 	}, serveHandler)
 }
 
+// MarkdownPage is the binding of the template which renders the pages of the StaticMarkdown
+type MarkdownPage struct {
+	// Name the path of the markdown file inside the directory, without the .md extension, i.e "guides/install"
+	Name string
+	// Front the yaml front matter of the markdown file
+	Front map[string]interface{}
+	// Content the html which is converted from the markdown
+	Content template.HTML
+}
+
+// FileSystemHandlerFunc returns a HandlerFunc which serves the files of a http.FileSystem by the "filepath" parameter of the route,
+// a directory is served by its index.html
+func FileSystemHandlerFunc(fs http.FileSystem) HandlerFunc {
+	return func(ctx *Context) {
+		serveFile(ctx, fs, ctx.Param("filepath"))
+	}
+}
+
+// serveFile serves a file of a http.FileSystem, a directory is served by its index.html
+func serveFile(ctx *Context, fs http.FileSystem, name string) {
+	f, info, err := openFile(fs, path.Clean("/"+name))
+	if err != nil {
+		ctx.NotFound()
+		return
+	}
+	defer f.Close()
+	ctx.Response.Header.Set(AcceptRanges, "bytes")
+	if byteRange := ctx.Request.Header.Peek(Range); len(byteRange) > 0 {
+		ctx.HandleError(serveFileRange(ctx, f, info, byteRange))
+		return
+	}
+	ctx.HandleError(ctx.ServeContent(f, info.Name(), info.ModTime()))
+}
+
+// serveFileRange serves the byte range of the Range header of a file, a range which can't be satisfied is responded with the 416
+func serveFileRange(ctx *Context, f http.File, info os.FileInfo, byteRange []byte) error {
+	size := int(info.Size())
+	start, end, err := fasthttp.ParseByteRange(byteRange, size)
+	if err != nil {
+		ctx.Response.Header.Set(ContentRange, "bytes */"+strconv.Itoa(size))
+		ctx.EmitError(StatusRequestedRangeNotSatisfiable)
+		return nil
+	}
+	if _, err = f.Seek(int64(start), 0); err != nil {
+		return ErrServeContent.With(err)
+	}
+
+	ctx.Response.Header.Set(ContentType, utils.TypeByExtension(info.Name()))
+	ctx.Response.Header.Set(LastModified, info.ModTime().UTC().Format(TimeFormat))
+	ctx.Response.Header.SetContentRange(start, end, size)
+	ctx.SetStatusCode(StatusPartialContent)
+	_, err = io.CopyN(ctx.Response.BodyWriter(), f, int64(end-start+1))
+	return ErrServeContent.With(err)
+}
+
+// openFile opens a file of a http.FileSystem, a directory is opened by its index.html
+func openFile(fs http.FileSystem, name string) (http.File, os.FileInfo, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err == nil && info.IsDir() {
+		f.Close()
+		if f, err = fs.Open(path.Join(name, "index.html")); err != nil {
+			return nil, nil, err
+		}
+		if info, err = f.Stat(); err == nil && info.IsDir() {
+			err = os.ErrNotExist
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, info, nil
+}
+
+// statFile returns the os.FileInfo of a file or a directory of a http.FileSystem
+func statFile(fs http.FileSystem, name string) (os.FileInfo, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Stat()
+}
+
+// StaticFileSystem registers a route which serves the files of a http.FileSystem, a directory is served by its index.html, the byte ranges are supported
+// accepts two parameters
+// first parameter is the request url path (string)
+// second parameter is the file system (http.FileSystem), i.e utils.NewMapFS, utils.NewZipFS or utils.NewAssetFS, so the files can be embedded into the binary
+// the "/public/css/main.css" is served by the /css/main.css file of the file system
+func (p *GardenParty) StaticFileSystem(relative string, fs http.FileSystem) {
+	if relative[len(relative)-1] != SlashByte {
+		relative += "/"
+	}
+
+	p.Get(relative+"*filepath", FileSystemHandlerFunc(fs))
+}
+
+// StaticAssets registers a route which serves the files of the assets at their fingerprinted urls, with a far-future Cache-Control,
+// and registers the "asset" template func to the render config of the party (or the station's), i.e {{ asset "css/app.css" }}
+// returns the "/static/css/app.3f2a1b9c0d.css"
+// accepts two parameters
+// first parameter is the request url path (string)
+// second parameter is the assets (*Assets), look NewAssets and NewAssetsFromManifest
+//
+// Note: call it before the server's start, the templates are loaded then
+func (p *GardenParty) StaticAssets(relative string, assets *Assets) {
+	if relative[len(relative)-1] != SlashByte {
+		relative += "/"
+	}
+	assets.prefix = strings.TrimSuffix(absPath(p.relativePath, relative), Slash)
+
+	renderCfg := p.station.Config.Render
+	if p.render != nil {
+		renderCfg = p.render.config
+	}
+	if renderCfg != nil {
+		renderCfg.Funcs = append(renderCfg.Funcs, assets.Funcs())
+	}
+
+	p.Get(relative+"*filepath", assets.Serve)
+}
+
+// MarkdownHandlerFunc returns a HandlerFunc which serves the .md files of a system directory as html pages
+// the "/guides/install" and the "/guides/install.md" are served by the guides/install.md file, a directory is served by its index.md.
+// The html and the front matter of the file are passed as MarkdownPage to the templateName template, which is the layout of the pages
+func MarkdownHandlerFunc(systemPath string, templateName string) HandlerFunc {
+	return MarkdownFileSystemHandlerFunc(http.Dir(systemPath), templateName)
+}
+
+// MarkdownFileSystemHandlerFunc same as MarkdownHandlerFunc but the .md files are served from a http.FileSystem
+func MarkdownFileSystemHandlerFunc(fs http.FileSystem, templateName string) HandlerFunc {
+	return func(ctx *Context) {
+		// clean the path, so it can't go outside of the directory
+		name := strings.TrimSuffix(path.Clean("/"+ctx.Param("filepath")), ".md")
+		if info, err := statFile(fs, name); err == nil && info.IsDir() {
+			name = path.Join(name, "index")
+		}
+
+		source, err := utils.ReadFile(fs, name+".md")
+		if err != nil {
+			ctx.NotFound()
+			return
+		}
+
+		front, markdown, err := render.ParseFrontMatter(source)
+		if err != nil {
+			ctx.HandleError(err)
+			return
+		}
+
+		ctx.Render(templateName, MarkdownPage{
+			Name:    name[1:],
+			Front:   front,
+			Content: ctx.renderer().MarkdownHTML(markdown),
+		})
+	}
+}
+
+// StaticMarkdown registers a route which serves the .md files of a system directory as html pages
+// accepts three parameters
+// first parameter is the request url path (string)
+// second parameter is the system directory (string)
+// third parameter is the template (string) which renders the pages, it receives a MarkdownPage, i.e {{ .Front.title }} and {{ .Content }}
+// the "/docs/guides/install" is served by the systemPath/guides/install.md and the "/docs/" by the systemPath/index.md
+func (p *GardenParty) StaticMarkdown(relative string, systemPath string, templateName string) {
+	if relative[len(relative)-1] != SlashByte {
+		relative += "/"
+	}
+
+	p.Get(relative+"*filepath", MarkdownHandlerFunc(systemPath, templateName))
+}
+
+// StaticMarkdownFileSystem same as StaticMarkdown but the .md files are served from a http.FileSystem,
+// i.e utils.NewMapFS, utils.NewZipFS or utils.NewAssetFS, so they can be embedded into the binary
+func (p *GardenParty) StaticMarkdownFileSystem(relative string, fs http.FileSystem, templateName string) {
+	if relative[len(relative)-1] != SlashByte {
+		relative += "/"
+	}
+
+	p.Get(relative+"*filepath", MarkdownFileSystemHandlerFunc(fs, templateName))
+}
+
 // Party is just a group joiner of routes which have the same prefix and share same middleware(s) also.
 // Party can also be named as 'Join' or 'Node' or 'Group' , Party chosen because it has more fun
 func (p *GardenParty) Party(path string, handlersFn ...HandlerFunc) IParty {
 	middleware := ConvertToHandlers(handlersFn)
+	var done Middleware
+	var partyRender *partyRender
 	if path[0] != SlashByte && strings.Contains(path, ".") {
 		//it's domain so no handlers share (even the global ) or path, nothing.
 	} else {
@@ -320,9 +664,13 @@ func (p *GardenParty) Party(path string, handlersFn ...HandlerFunc) IParty {
 		path = absPath(p.relativePath, path)
 		// append the parent's +child's handlers
 		middleware = JoinMiddleware(p.middleware, middleware)
+		// the parent's done handlers too
+		done = JoinMiddleware(p.done, nil)
+		// and the parent's templates
+		partyRender = p.render
 	}
 
-	return &GardenParty{relativePath: path, station: p.station, middleware: middleware}
+	return &GardenParty{relativePath: path, station: p.station, middleware: middleware, done: done, render: partyRender}
 }
 
 func absPath(rootPath string, relativePath string) (absPath string) {
@@ -0,0 +1,257 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris/utils"
+)
+
+// newTestTemplatesIris returns a new Iris with the logger disabled and the templates of the files
+func newTestTemplatesIris(files map[string][]byte) *Iris {
+	config := DefaultConfig()
+	config.Log = false
+	config.Render.Directory = "/"
+	config.Render.FileSystem = utils.NewMapFS(files)
+	return New(config)
+}
+
+func TestStaticMarkdown(t *testing.T) {
+	s := newTestTemplatesIris(map[string][]byte{
+		"page.html": []byte(`<title>{{.Front.title}}</title><p>{{.Name}}</p>{{.Content}}`),
+	})
+	s.StaticMarkdownFileSystem("/docs", utils.NewMapFS(map[string][]byte{
+		"index.md":          []byte("# Docs\n"),
+		"guides/install.md": []byte("---\ntitle: Install\n---\n*go get*\n"),
+		"broken.md":         []byte("---\ntitle: [\n---\n"),
+		"secret.txt":        []byte("secret"),
+	}), "page")
+	testServe(s)
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/docs/", StatusOK, "<title></title><p>index</p><h1>Docs</h1>"},
+		{"/docs/guides/install", StatusOK, "<title>Install</title><p>guides/install</p><p><em>go get</em></p>"},
+		{"/docs/guides/install.md", StatusOK, "<title>Install</title><p>guides/install</p><p><em>go get</em></p>"},
+		{"/docs/guides/missing", StatusNotFound, ""},
+		{"/docs/secret.txt", StatusNotFound, ""},
+		{"/docs/../docs/guides/install", StatusOK, "<title>Install</title>"},
+		{"/docs/broken", StatusInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		reqCtx := testRequest(s, MethodGet, tt.path, "")
+		if status := reqCtx.Response.StatusCode(); status != tt.status {
+			t.Fatalf("%s: expected status code %d but got %d", tt.path, tt.status, status)
+		}
+		if body := strings.Replace(string(reqCtx.Response.Body()), "\n", "", -1); tt.body != "" && !strings.HasPrefix(body, tt.body) {
+			t.Fatalf("%s: expected the body %q but got %q", tt.path, tt.body, body)
+		}
+	}
+}
+
+func TestPartySetRenderConfig(t *testing.T) {
+	s := newTestTemplatesIris(map[string][]byte{
+		"index.html":  []byte(`site {{.}}`),
+		"layout.html": []byte(`<site>{{ yield }}</site>`),
+	})
+	s.Config.Render.Layout = "layout"
+
+	admin := s.Party("/admin")
+	// registed before the SetRenderConfig, it keeps the station's render
+	admin.Get("/before", func(ctx *Context) {
+		ctx.Render("index", "before")
+	})
+	admin.SetRenderConfig(&RenderConfig{
+		Directory:  "/",
+		FileSystem: utils.NewMapFS(map[string][]byte{"index.html": []byte(`admin {{.}}`), "layout.html": []byte(`<admin>{{ yield }}</admin>`)}),
+		Layout:     "layout",
+		IndentJSON: true,
+	})
+	admin.Get("/", func(ctx *Context) {
+		ctx.Render("index", "home")
+	})
+	admin.Get("/json", func(ctx *Context) {
+		ctx.JSON(StatusOK, map[string]int{"a": 1})
+	})
+	// the child parties use the render of their parent
+	admin.Party("/users").Get("/", func(ctx *Context) {
+		ctx.Render("index", "users")
+	})
+
+	s.Get("/", func(ctx *Context) {
+		ctx.Render("index", "home")
+	})
+	s.Get("/json", func(ctx *Context) {
+		ctx.JSON(StatusOK, map[string]int{"a": 1})
+	})
+	testServe(s)
+
+	expectResponse(t, testRequest(s, MethodGet, "/", ""), StatusOK, "<site>site home</site>")
+	expectResponse(t, testRequest(s, MethodGet, "/json", ""), StatusOK, `{"a":1}`)
+	expectResponse(t, testRequest(s, MethodGet, "/admin", ""), StatusOK, "<admin>admin home</admin>")
+	expectResponse(t, testRequest(s, MethodGet, "/admin/json", ""), StatusOK, "{\n  \"a\": 1\n}\n")
+	expectResponse(t, testRequest(s, MethodGet, "/admin/users", ""), StatusOK, "<admin>admin users</admin>")
+	expectResponse(t, testRequest(s, MethodGet, "/admin/before", ""), StatusOK, "<site>site before</site>")
+}
+
+func TestStatic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	bigCSS := strings.Repeat("body { color: red; }\n", 100)
+	files := map[string]string{
+		"index.html":       "<h1>index</h1>",
+		"css/app.css":      bigCSS,
+		"files/readme.txt": "readme",
+	}
+	embedded := make(map[string][]byte, len(files))
+	for name, contents := range files {
+		embedded[name] = []byte(contents)
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := newTestIris()
+	s.Static("/public", dir, 1)
+	s.Static("/files", dir, 0)
+	s.StaticWeb("/web", dir, 1)
+	s.StaticFS("/fs", dir, 1)
+	s.Party("/admin").Static("/assets", dir, 2)
+	s.StaticFileSystem("/embedded", utils.NewMapFS(embedded))
+	testServe(s)
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/public/css/app.css", StatusOK, bigCSS},
+		{"/public/index.html", StatusOK, "<h1>index</h1>"},
+		{"/public/missing.css", StatusNotFound, ""},
+		{"/files/readme.txt", StatusOK, "readme"},
+		{"/web/", StatusOK, "<h1>index</h1>"},
+		{"/web/css/app.css", StatusOK, bigCSS},
+		{"/fs/css/app.css", StatusOK, bigCSS},
+		{"/admin/assets/css/app.css", StatusOK, bigCSS},
+		{"/embedded/css/app.css", StatusOK, bigCSS},
+		{"/embedded/", StatusOK, "<h1>index</h1>"},
+		{"/embedded/missing.css", StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		reqCtx := testRequest(s, MethodGet, tt.path, "")
+		if status := reqCtx.Response.StatusCode(); status != tt.status {
+			t.Fatalf("%s: expected status code %d but got %d", tt.path, tt.status, status)
+		}
+		if tt.status == StatusOK {
+			expectResponse(t, reqCtx, tt.status, tt.body)
+		}
+	}
+
+	// the StaticFS lists the directories and compresses by the Accept-Encoding, the Static doesn't
+	reqCtx := testRequest(s, MethodGet, "/fs/css/", "")
+	if body := string(reqCtx.Response.Body()); reqCtx.Response.StatusCode() != StatusOK || !strings.Contains(body, "app.css") {
+		t.Fatalf("expected the listing of the directory but got %d, %q", reqCtx.Response.StatusCode(), body)
+	}
+	if reqCtx = testRequest(s, MethodGet, "/public/css/", ""); reqCtx.Response.StatusCode() == StatusOK {
+		t.Fatal("expected the directory not to be listed")
+	}
+
+	reqCtx = testRequest(s, MethodGet, "/fs/css/app.css", "", "Accept-Encoding", "gzip")
+	if encoding := string(reqCtx.Response.Header.Peek("Content-Encoding")); encoding != "gzip" {
+		t.Fatalf("expected the gzip Content-Encoding but got %q", encoding)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(reqCtx.Response.Body()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := ioutil.ReadAll(r); err != nil || string(body) != bigCSS {
+		t.Fatalf("expected the decompressed css but got %q, %v", body, err)
+	}
+
+	reqCtx = testRequest(s, MethodGet, "/public/css/app.css", "", "Accept-Encoding", "gzip")
+	if encoding := string(reqCtx.Response.Header.Peek("Content-Encoding")); encoding != "" {
+		t.Fatalf("expected no Content-Encoding but got %q", encoding)
+	}
+}
+
+func TestStaticRange(t *testing.T) {
+	files := map[string][]byte{"video.txt": []byte("0123456789")}
+	s := newTestIris()
+	s.StaticFileSystem("/embedded", utils.NewMapFS(files))
+	testServe(s)
+
+	reqCtx := testRequest(s, MethodGet, "/embedded/video.txt", "")
+	expectResponse(t, reqCtx, StatusOK, "0123456789")
+	if accept := string(reqCtx.Response.Header.Peek(AcceptRanges)); accept != "bytes" {
+		t.Fatalf("expected the bytes Accept-Ranges but got %q", accept)
+	}
+
+	tests := []struct {
+		byteRange    string
+		body         string
+		contentRange string
+	}{
+		{"bytes=2-5", "2345", "bytes 2-5/10"},
+		{"bytes=7-", "789", "bytes 7-9/10"},
+		{"bytes=-3", "789", "bytes 7-9/10"},
+		{"bytes=8-20", "89", "bytes 8-9/10"},
+	}
+	for _, tt := range tests {
+		reqCtx = testRequest(s, MethodGet, "/embedded/video.txt", "", Range, tt.byteRange)
+		expectResponse(t, reqCtx, StatusPartialContent, tt.body)
+		if contentRange := string(reqCtx.Response.Header.Peek(ContentRange)); contentRange != tt.contentRange {
+			t.Fatalf("%s: expected the Content-Range %q but got %q", tt.byteRange, tt.contentRange, contentRange)
+		}
+	}
+
+	reqCtx = testRequest(s, MethodGet, "/embedded/video.txt", "", Range, "bytes=10-")
+	if status := reqCtx.Response.StatusCode(); status != StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("expected status code %d but got %d", StatusRequestedRangeNotSatisfiable, status)
+	}
+	if contentRange := string(reqCtx.Response.Header.Peek(ContentRange)); contentRange != "bytes */10" {
+		t.Fatalf("expected the Content-Range bytes */10 but got %q", contentRange)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+)
+
+// ProblemXMLNamespace is the xml namespace of the problem details objects
+const ProblemXMLNamespace = "urn:ietf:rfc:7807"
+
+// Problem is a problem details object (RFC 7807), the machine-readable error of a http api,
+// it's sent by the ctx.Problem as application/problem+json or application/problem+xml.
+//
+// A Problem is an error too, with its Status as the ErrorStatusCoder's status code,
+// so a HandlerFuncE can return it and the parties which use the ProblemErrors send it as it is.
+type Problem struct {
+	// Type a URI reference which identifies the problem type, "about:blank" if empty
+	Type string
+	// Title a short summary of the problem type, the status text if empty and the Type is empty too
+	Title string
+	// Status the http status code
+	Status int
+	// Detail the explanation of this occurrence of the problem
+	Detail string
+	// Instance a URI reference which identifies this occurrence of the problem, i.e the request path
+	Instance string
+	// Extensions the extension members, they are written next to the standard members, i.e "balance" or "invalid_params"
+	Extensions map[string]interface{}
+}
+
+// NewProblem returns a Problem of the status code, its Title is the status text
+func NewProblem(status int, detail string) Problem {
+	return Problem{Title: StatusText(status), Status: status, Detail: detail}
+}
+
+// Error returns the title and the detail of the problem, it implements the error
+func (p Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Title + ": " + p.Detail
+}
+
+// StatusCode returns the Status, it implements the ErrorStatusCoder
+func (p Problem) StatusCode() int {
+	return p.Status
+}
+
+// members returns the members of the problem object, the standard members override the extension members of the same name
+func (p Problem) members() map[string]interface{} {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+	for k, v := range map[string]string{"type": p.Type, "title": p.Title, "detail": p.Detail, "instance": p.Instance} {
+		if v != "" {
+			members[k] = v
+		} else {
+			delete(members, k)
+		}
+	}
+	if p.Status > 0 {
+		members["status"] = p.Status
+	}
+	return members
+}
+
+// MarshalJSON writes the problem as a json object, the extension members are members of the object
+func (p Problem) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.members())
+}
+
+// MarshalXML writes the problem as a <problem> element of the RFC 7807 namespace, each member is a child element
+func (p Problem) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Space: ProblemXMLNamespace, Local: "problem"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	members := p.members()
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := e.EncodeElement(members[name], xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+			return fmt.Errorf("problem member %s: %s", name, err.Error())
+		}
+	}
+	return e.EncodeToken(start.End())
+}
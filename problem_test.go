@@ -0,0 +1,182 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris/render"
+)
+
+func TestProblemMarshal(t *testing.T) {
+	p := Problem{
+		Type:       "https://example.com/probs/out-of-credit",
+		Title:      "You do not have enough credit.",
+		Status:     StatusForbidden,
+		Detail:     "Your current balance is 30, but that costs 50.",
+		Instance:   "/account/12345/msgs/abc",
+		Extensions: map[string]interface{}{"balance": 30, "title": "overridden"},
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"balance":30,"detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc",` +
+		`"status":403,"title":"You do not have enough credit.","type":"https://example.com/probs/out-of-credit"}`
+	if string(b) != expected {
+		t.Fatalf("expected the json %s but got %s", expected, b)
+	}
+
+	b, err = xml.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `<problem xmlns="urn:ietf:rfc:7807"><balance>30</balance><detail>Your current balance is 30, but that costs 50.</detail>` +
+		`<instance>/account/12345/msgs/abc</instance><status>403</status><title>You do not have enough credit.</title>` +
+		`<type>https://example.com/probs/out-of-credit</type></problem>`
+	if string(b) != expected {
+		t.Fatalf("expected the xml %s but got %s", expected, b)
+	}
+
+	// the empty members are omitted, even if an extension has their name
+	b, _ = json.Marshal(Problem{Extensions: map[string]interface{}{"detail": "extension"}})
+	if string(b) != `{}` {
+		t.Fatalf("expected an empty object but got %s", b)
+	}
+
+	if err := error(NewProblem(StatusNotFound, "no such user")); err.Error() != "Not Found: no such user" {
+		t.Fatalf("unexpected error text %q", err.Error())
+	}
+}
+
+func TestContextProblem(t *testing.T) {
+	s := newTestIris()
+	s.Get("/pay", func(ctx *Context) {
+		ctx.Problem(StatusPaymentRequired, Problem{Detail: "out of credit", Extensions: map[string]interface{}{"balance": 30}})
+	})
+	testServe(s)
+
+	tests := []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"", render.ContentProblemJSON, `{"balance":30,"detail":"out of credit","status":402,"title":"Payment Required"}`},
+		{"application/json", render.ContentProblemJSON, `{"balance":30,"detail":"out of credit","status":402,"title":"Payment Required"}`},
+		{"text/html, application/xml;q=0.9", render.ContentProblemXML,
+			`<problem xmlns="urn:ietf:rfc:7807"><balance>30</balance><detail>out of credit</detail><status>402</status><title>Payment Required</title></problem>`},
+		{"application/problem+xml, application/json;q=0.5", render.ContentProblemXML,
+			`<problem xmlns="urn:ietf:rfc:7807"><balance>30</balance><detail>out of credit</detail><status>402</status><title>Payment Required</title></problem>`},
+	}
+	for _, tt := range tests {
+		reqCtx := testRequest(s, MethodGet, "/pay", "", "Accept", tt.accept)
+		expectResponse(t, reqCtx, StatusPaymentRequired, tt.body)
+		if contentType := string(reqCtx.Response.Header.ContentType()); !strings.HasPrefix(contentType, tt.contentType) {
+			t.Fatalf("%s: expected the content type %s but got %s", tt.accept, tt.contentType, contentType)
+		}
+		if vary := string(reqCtx.Response.Header.Peek("Vary")); vary != "Accept" {
+			t.Fatalf("expected the Vary: Accept but got %q", vary)
+		}
+	}
+}
+
+func newTestProblemIris(isDevelopment bool) *Iris {
+	s := newTestIris()
+	s.Config.Render.IsDevelopment = isDevelopment
+	api := s.Party("/api")
+	api.ProblemErrors()
+	api.GetE("/users/:id", func(ctx *Context) error {
+		return Problem{Type: "https://example.com/probs/no-user", Title: "No such user", Status: StatusNotFound}
+	})
+	api.GetE("/fail", func(ctx *Context) error {
+		return errors.New("database is down")
+	})
+	s.GetE("/fail", func(ctx *Context) error {
+		return errors.New("database is down")
+	})
+	return s
+}
+
+func TestProblemErrors(t *testing.T) {
+	s := newTestProblemIris(false)
+	testServe(s)
+
+	// the unregistered paths under the party are problems too
+	expectResponse(t, testRequest(s, MethodGet, "/api/missing", ""), StatusNotFound,
+		`{"detail":"404 not found","instance":"/api/missing","status":404,"title":"Not Found"}`)
+	// a returned Problem is sent as it is
+	expectResponse(t, testRequest(s, MethodGet, "/api/users/42", ""), StatusNotFound,
+		`{"instance":"/api/users/42","status":404,"title":"No such user","type":"https://example.com/probs/no-user"}`)
+	// the error is hidden on production
+	expectResponse(t, testRequest(s, MethodGet, "/api/fail", ""), StatusInternalServerError,
+		`{"detail":"The server encountered an unexpected condition which prevented it from fulfilling the request.",`+
+			`"instance":"/api/fail","status":500,"title":"Internal Server Error"}`)
+
+	// the paths out of the party are not affected
+	expectResponse(t, testRequest(s, MethodGet, "/apix", ""), StatusNotFound, "404 not found")
+	expectResponse(t, testRequest(s, MethodGet, "/fail", ""), StatusInternalServerError,
+		"The server encountered an unexpected condition which prevented it from fulfilling the request.")
+}
+
+func TestProblemErrorsDevelopment(t *testing.T) {
+	s := newTestProblemIris(true)
+	testServe(s)
+
+	expectResponse(t, testRequest(s, MethodGet, "/api/fail", ""), StatusInternalServerError,
+		`{"detail":"The server encountered an unexpected condition which prevented it from fulfilling the request.",`+
+			`"error":"database is down","instance":"/api/fail","status":500,"title":"Internal Server Error"}`)
+}
+
+func TestIsProblemPath(t *testing.T) {
+	he := defaultHTTPErrors()
+	he.ProblemErrorsFor("/api/")
+	he.ProblemErrorsFor("api.example.com")
+
+	tests := []struct {
+		host     string
+		path     string
+		expected bool
+	}{
+		{"example.com", "/api", true},
+		{"example.com", "/api/users", true},
+		{"example.com", "/apis", false},
+		{"example.com", "/", false},
+		{"api.example.com", "/", true},
+		{"api.example.com", "/users", true},
+	}
+	for _, tt := range tests {
+		if got := he.isProblemPath(tt.host, tt.path); got != tt.expected {
+			t.Fatalf("%s%s: expected %v but got %v", tt.host, tt.path, tt.expected, got)
+		}
+	}
+}
@@ -29,6 +29,7 @@ package iris
 
 import (
 	"html/template"
+	"net/http"
 
 	"github.com/kataras/iris/render"
 )
@@ -52,8 +53,11 @@ type HTMLOptions struct {
 // RenderConfig is a struct for specifying configuration options for the render.Render object.
 type RenderConfig struct {
 	// Directory to load templates. Default is "templates".
+	// If the FileSystem (or the Asset) is set, it's the directory of the templates inside it.
 	Directory string
-	// Asset function to use in place of directory. Defaults to nil.
+	// FileSystem to load the templates from, in place of the OS, i.e utils.NewMapFS, utils.NewZipFS or http.Dir. Defaults to nil.
+	FileSystem http.FileSystem
+	// Asset function to use in place of directory, it's an alternative to the FileSystem for the go-bindata. Defaults to nil.
 	Asset func(name string) ([]byte, error)
 	// AssetNames function to use in place of directory. Defaults to nil.
 	AssetNames func() []string
@@ -67,8 +71,14 @@ type RenderConfig struct {
 	Delims Delims
 	// Appends the given character set to the Content-Type header. Default is "UTF-8".
 	Charset string
-	// Gzip enable it if you want to render using gzip compression. Default is false
+	// Gzip enable it if you want to render using compression, the encoding (br, gzip or deflate)
+	// is negotiated per request with the client's Accept-Encoding header. Default is false
 	Gzip bool
+	// CompressEncodings the encodings which are offered to the clients when Gzip is enabled, in order of preference.
+	// Default is ["br", "gzip", "deflate"]
+	CompressEncodings []string
+	// CompressMinLength bodies smaller than this length (in bytes) are not compressed. Default is 1024
+	CompressMinLength int
 	// Outputs human readable JSON.
 	IndentJSON bool
 	// Outputs human readable XML. Default is false.
@@ -79,18 +89,48 @@ type RenderConfig struct {
 	PrefixXML []byte
 	// Allows changing of output to XHTML instead of HTML. Default is "text/html".
 	HTMLContentType string
-	// If IsDevelopment is set to true, this will recompile the templates on every request. Default is false.
+	// If IsDevelopment is set to true, this will recompile the templates when a file under the Directory changes
+	// (or on every request if the FileSystem or the Asset is used) and the compile errors are shown as an error page. Default is false.
 	IsDevelopment bool
 	// Unescape HTML characters "&<>" to their original values. Default is false.
 	UnEscapeHTML bool
 	// Streams JSON responses instead of marshalling prior to sending. Default is false.
 	StreamingJSON bool
+	// StreamingHTML set to true to execute the templates straight into the response's body stream, instead of a buffer,
+	// it lowers the memory and the time to the first byte for large pages. The first StreamingHTMLHeadSize bytes are still buffered,
+	// so the errors which happen before that are rendered as usual, a later error truncates the response.
+	// The rest of the execution happens after the handler is returned, so the binding and the per-request funcs should not keep the Context.
+	// It's ignored on IsDevelopment. Default is false
+	StreamingHTML bool
+	// StreamingHTMLHeadSize the size of the buffered head of the StreamingHTML. Default is 4096
+	StreamingHTMLHeadSize int
+	// MarkdownSanitize set to true to clean the html which is converted from markdown from any unsafe html, i.e scripts.
+	// Enable it if the markdown contents are coming from your users. Default is false
+	MarkdownSanitize bool
 	// Require that all partials executed in the layout are implemented in all templates using the layout. Default is false.
 	RequirePartials bool
 	// Deprecated: Use the above `RequirePartials` instead of this. As of Go 1.6, blocks are built in. Default is false.
 	RequireBlocks bool
 	// Disables automatic rendering of http.StatusInternalServerError when an error occurs. Default is false.
 	DisableHTTPErrorRendering bool
+	// Engine the template engine which loads and executes the templates
+	// render.NewHTMLEngine(), render.NewPongo2Engine() and render.NewHandlebarsEngine() are included,
+	// or use your own render.TemplateEngine. Default is nil, which means the html/template engine
+	Engine render.TemplateEngine
+}
+
+// partyRender is the first handler of the routes of a party which has its own RenderConfig,
+// it passes the party's render to the Context
+type partyRender struct {
+	config *RenderConfig
+	// render is created with the station's render, on DoPostListen
+	render *render.Render
+}
+
+// Serve sets the party's render to the Context and continues to the next handler
+func (p *partyRender) Serve(ctx *Context) {
+	ctx.render = p.render
+	ctx.Next()
 }
 
 // newRender returns a new render.Render from iris.RenderConfig, used inside New(...)
@@ -100,6 +140,7 @@ func newRender(config *RenderConfig) *render.Render {
 	}
 	options := render.Options{}
 	options.Directory = config.Directory
+	options.FileSystem = config.FileSystem
 	options.Asset = config.Asset
 	options.AssetNames = config.AssetNames
 	options.Layout = config.Layout
@@ -108,6 +149,8 @@ func newRender(config *RenderConfig) *render.Render {
 	options.Delims = render.Delims{config.Delims.Left, config.Delims.Right}
 	options.Charset = config.Charset
 	options.Gzip = config.Gzip
+	options.CompressEncodings = config.CompressEncodings
+	options.CompressMinLength = config.CompressMinLength
 	options.IndentJSON = config.IndentJSON
 	options.IndentXML = config.IndentXML
 	options.PrefixJSON = config.PrefixJSON
@@ -116,9 +159,13 @@ func newRender(config *RenderConfig) *render.Render {
 	options.IsDevelopment = config.IsDevelopment
 	options.UnEscapeHTML = config.UnEscapeHTML
 	options.StreamingJSON = config.StreamingJSON
+	options.StreamingHTML = config.StreamingHTML
+	options.StreamingHTMLHeadSize = config.StreamingHTMLHeadSize
+	options.MarkdownSanitize = config.MarkdownSanitize
 	options.RequirePartials = config.RequirePartials
 	options.RequireBlocks = config.RequireBlocks
 	options.DisableHTTPErrorRendering = config.DisableHTTPErrorRendering
+	options.Engine = config.Engine
 
 	if options.Charset == "" {
 		options.Charset = DefaultCharset
@@ -127,6 +174,23 @@ func newRender(config *RenderConfig) *render.Render {
 	return render.New(options)
 }
 
+// JSONStreamOptions is a struct for specifying options for a context.JSONStream call.
+type JSONStreamOptions struct {
+	// Array writes the values as a JSON array, which is built incrementally, instead of newline-delimited JSON. Default is false
+	Array bool
+	// FlushEvery sends the written values to the client after this number of values,
+	// a channel's values are sent also when the channel has no value ready. Default is 100
+	FlushEvery int
+}
+
+// parseJSONStreamOptions takes an iris.JSONStreamOptions and convert to render.JSONStreamOptions
+func parseJSONStreamOptions(options ...JSONStreamOptions) (opt []render.JSONStreamOptions) {
+	if len(options) > 0 {
+		opt = []render.JSONStreamOptions{render.JSONStreamOptions{Array: options[0].Array, FlushEvery: options[0].FlushEvery}}
+	}
+	return
+}
+
 // parseHTMLOptions takes an iris.HTMLOptions and convert to render.HTMLOptions
 func parseHTMLOptions(options ...HTMLOptions) (opt []render.HTMLOptions) {
 	if options != nil && len(options) > 0 {
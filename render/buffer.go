@@ -1,20 +1,38 @@
 package render
 
-import "bytes"
+import (
+	"bytes"
+	"sync/atomic"
+)
 
-// bufPool represents a reusable buffer pool for executing templates into.
-var bufPool *BufferPool
+const (
+	// minBufferSize the capacity of the new buffers before the pool has seen any buffer.
+	minBufferSize = 512
+	// maxBufferSizeFactor buffers which have grown larger than this times the average are not kept by the pool.
+	maxBufferSizeFactor = 4
+)
+
+// bufPool represents a reusable buffer pool for executing templates into,
+// it's used by the CompressResponse too, which may be called without a Render.
+var bufPool = NewBufferPool(64)
 
 // BufferPool implements a pool of bytes.Buffers in the form of a bounded channel.
 // Pulled from the github.com/oxtoacart/bpool package (Apache licensed).
+//
+// The pool is sized adaptively, it keeps a moving average of the length of the buffers which are given back,
+// the new buffers are allocated with that capacity and the buffers which have grown much larger are discarded,
+// so a few large pages don't keep large buffers alive.
 type BufferPool struct {
-	c chan *bytes.Buffer
+	// average is first, so it's 64-bit aligned for the atomic operations on 32-bit platforms
+	average int64
+	c       chan *bytes.Buffer
 }
 
 // NewBufferPool creates a new BufferPool bounded to the given size.
 func NewBufferPool(size int) (bp *BufferPool) {
 	return &BufferPool{
-		c: make(chan *bytes.Buffer, size),
+		average: minBufferSize,
+		c:       make(chan *bytes.Buffer, size),
 	}
 }
 
@@ -26,13 +44,31 @@ func (bp *BufferPool) Get() (b *bytes.Buffer) {
 	// reuse existing buffer
 	default:
 		// create new buffer
-		b = bytes.NewBuffer([]byte{})
+		b = bytes.NewBuffer(make([]byte, 0, atomic.LoadInt64(&bp.average)))
 	}
 	return
 }
 
 // Put returns the given Buffer to the BufferPool.
 func (bp *BufferPool) Put(b *bytes.Buffer) {
+	bp.PutLen(b, b.Len())
+}
+
+// PutLen same as Put but the length of the buffer's content is passed,
+// use it when the buffer is already read, i.e by its WriteTo, so its Len is zero.
+func (bp *BufferPool) PutLen(b *bytes.Buffer, n int) {
+	// the moving average gives 1/8 weight to the new length, it's not exact under concurrency but it doesn't have to be.
+	average := atomic.LoadInt64(&bp.average)
+	average += (int64(n) - average) / 8
+	if average < minBufferSize {
+		average = minBufferSize
+	}
+	atomic.StoreInt64(&bp.average, average)
+
+	if int64(b.Cap()) > maxBufferSizeFactor*average {
+		return // Discard the buffer, it's too large for the usual workload.
+	}
+
 	b.Reset()
 	select {
 	case bp.c <- b:
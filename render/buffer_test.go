@@ -0,0 +1,47 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBufferPoolReuse(t *testing.T) {
+	defer func(pool *BufferPool) { bufPool = pool }(bufPool)
+	bufPool = NewBufferPool(4)
+
+	files := map[string][]byte{"page.html": []byte(`{{ . }}`)}
+	r := newTestRender(NewHTMLEngine(), files)
+	page := strings.Repeat("a", 8*1024)
+
+	for i := 0; i < 50; i++ {
+		if body := string(renderHTML(t, r, "page", page).Response.Body()); body != page {
+			t.Fatalf("unexpected body of %d bytes", len(body))
+		}
+	}
+	if average := bufPool.average; average < int64(len(page))/2 {
+		t.Fatalf("expected the average to follow the %d bytes of the page but got %d", len(page), average)
+	}
+	if n := len(bufPool.c); n != 1 {
+		t.Fatalf("expected the buffer of the page to be kept by the pool but the pool has %d buffers", n)
+	}
+
+	// the next render takes the same buffer and gives it back
+	b := <-bufPool.c
+	bufPool.c <- b
+	renderHTML(t, r, "page", page)
+	if reused := <-bufPool.c; reused != b {
+		t.Fatal("expected the buffer to be reused")
+	}
+	if b.Cap() < len(page) {
+		t.Fatalf("expected the buffer to keep its %d bytes capacity but got %d", len(page), b.Cap())
+	}
+
+	// Put keeps the length of a buffer which is not read
+	pool := NewBufferPool(1)
+	b = pool.Get()
+	b.WriteString(page)
+	pool.Put(b)
+	if pool.average <= minBufferSize {
+		t.Fatalf("expected the average to grow but got %d", pool.average)
+	}
+}
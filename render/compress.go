@@ -0,0 +1,244 @@
+package render
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/gzip"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// Accept header constant.
+	Accept = "Accept"
+	// AcceptEncoding header constant.
+	AcceptEncoding = "Accept-Encoding"
+	// ContentEncoding header constant.
+	ContentEncoding = "Content-Encoding"
+	// Vary header constant.
+	Vary = "Vary"
+
+	// EncodingGzip the gzip content encoding.
+	EncodingGzip = "gzip"
+	// EncodingDeflate the deflate content encoding.
+	EncodingDeflate = "deflate"
+	// EncodingBrotli the brotli content encoding.
+	EncodingBrotli = "br"
+
+	// DefaultCompressMinLength bodies smaller than this size (in bytes) are sent uncompressed.
+	DefaultCompressMinLength = 1024
+)
+
+// DefaultCompressEncodings the encodings which are offered to the clients, in order of preference.
+var DefaultCompressEncodings = []string{EncodingBrotli, EncodingGzip, EncodingDeflate}
+
+// CompressWriter is implemented by the gzip, flate and brotli writers.
+type CompressWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+var compressWriterPools = map[string]*sync.Pool{
+	EncodingGzip: {New: func() interface{} {
+		return gzip.NewWriter(nil)
+	}},
+	EncodingDeflate: {New: func() interface{} {
+		w, _ := flate.NewWriter(nil, flate.DefaultCompression)
+		return w
+	}},
+	EncodingBrotli: {New: func() interface{} {
+		return brotli.NewWriterLevel(nil, brotli.DefaultCompression)
+	}},
+}
+
+// AcquireCompressWriter returns a pooled writer which compresses, with the given encoding ("gzip", "deflate" or "br"), to the w.
+// Close it when done and give it back with ReleaseCompressWriter.
+func AcquireCompressWriter(w io.Writer, encoding string) (CompressWriter, error) {
+	pool, ok := compressWriterPools[encoding]
+	if !ok {
+		return nil, ErrUnsupportedEncoding.Format(encoding)
+	}
+	cw := pool.Get().(CompressWriter)
+	cw.Reset(w)
+	return cw, nil
+}
+
+// ReleaseCompressWriter puts back to the pool a writer which is taken by AcquireCompressWriter.
+func ReleaseCompressWriter(encoding string, cw CompressWriter) {
+	if pool, ok := compressWriterPools[encoding]; ok {
+		pool.Put(cw)
+	}
+}
+
+// Compress writes the data, compressed with the given encoding ("gzip", "deflate" or "br"), to the w.
+func Compress(w io.Writer, encoding string, data []byte) error {
+	cw, err := AcquireCompressWriter(w, encoding)
+	if err != nil {
+		return err
+	}
+	_, err = cw.Write(data)
+	if closeErr := cw.Close(); err == nil {
+		err = closeErr
+	}
+	ReleaseCompressWriter(encoding, cw)
+	return err
+}
+
+// NegotiateEncoding returns the best of the offered encodings which the client accepts
+// based on the value of its Accept-Encoding header, the q-values are respected and ties are resolved by the order of the offers.
+// Returns an empty string if none of the offers is acceptable, the response should be sent uncompressed then.
+func NegotiateEncoding(acceptEncoding string, offers []string) string {
+	if acceptEncoding == "" || len(offers) == 0 {
+		return ""
+	}
+
+	qualities := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, q := parseQuality(part)
+		if name == "" {
+			continue
+		}
+		if name == "*" {
+			wildcard = q
+			continue
+		}
+		qualities[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, ok := qualities[offer]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// parseQuality parses a single "name;q=0.8" item of the Accept-Encoding or the Accept header.
+func parseQuality(part string) (string, float64) {
+	params := strings.Split(part, ";")
+	name := strings.ToLower(strings.TrimSpace(params[0]))
+	q := 1.0
+	for _, param := range params[1:] {
+		param = strings.TrimSpace(param)
+		if !strings.HasPrefix(param, "q=") && !strings.HasPrefix(param, "Q=") {
+			continue
+		}
+		if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+			q = v
+		}
+	}
+	return name, q
+}
+
+// NegotiateContentType returns the best of the offered content types which the client accepts
+// based on the value of its Accept header, the media ranges ("application/*", "*/*") match too and the most specific one is used.
+// The q-values are respected and ties are resolved by the order of the offers.
+// Returns an empty string if none of the offers is acceptable.
+func NegotiateContentType(accept string, offers []string) string {
+	if accept == "" || len(offers) == 0 {
+		return ""
+	}
+
+	type mediaRange struct {
+		name string
+		q    float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		if name, q := parseQuality(part); name != "" {
+			ranges = append(ranges, mediaRange{name: name, q: q})
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for _, r := range ranges {
+			s := -1
+			switch {
+			case r.name == offer:
+				s = 2
+			case strings.HasSuffix(r.name, "/*") && strings.HasPrefix(offer, r.name[0:len(r.name)-1]):
+				s = 1
+			case r.name == "*/*" || r.name == "*":
+				s = 0
+			}
+			if s > specificity {
+				q, specificity = r.q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// AddVary appends the field to the Vary header of the response, if it's not already there.
+func AddVary(h *fasthttp.ResponseHeader, field string) {
+	vary := string(h.Peek(Vary))
+	if vary == "" {
+		h.Set(Vary, field)
+		return
+	}
+	for _, v := range strings.Split(vary, ",") {
+		if v = strings.TrimSpace(v); v == "*" || strings.EqualFold(v, field) {
+			return
+		}
+	}
+	h.Set(Vary, vary+", "+field)
+}
+
+// compressBody replaces the response's body with its compressed, by the encoding, form.
+func compressBody(ctx *fasthttp.RequestCtx, encoding string) error {
+	out := bufPool.Get()
+	defer bufPool.Put(out)
+	if err := Compress(out, encoding, ctx.Response.Body()); err != nil {
+		return err
+	}
+	ctx.Response.SetBody(out.Bytes())
+	ctx.Response.Header.Set(ContentEncoding, encoding)
+	return nil
+}
+
+// CompressResponse negotiates the encoding with the client and compresses the (not streaming) body of the response,
+// bodies smaller than the minLength, partial and already encoded responses are left as they are.
+// The Vary: Accept-Encoding header is always set, the response depends on it even if it's not compressed.
+func CompressResponse(ctx *fasthttp.RequestCtx, encodings []string, minLength int) error {
+	AddVary(&ctx.Response.Header, AcceptEncoding)
+
+	if ctx.Response.IsBodyStream() || len(ctx.Response.Body()) < minLength || len(ctx.Response.Header.Peek(ContentEncoding)) > 0 ||
+		len(ctx.Response.Header.Peek("Content-Range")) > 0 {
+		return nil
+	}
+	encoding := NegotiateEncoding(string(ctx.Request.Header.Peek(AcceptEncoding)), encodings)
+	if encoding == "" {
+		return nil
+	}
+	return compressBody(ctx, encoding)
+}
+
+// streamEncoding returns the encoding of the streaming responses, which is negotiated with the client if the Options.Gzip is true,
+// the streams are always compressed, the Options.CompressMinLength is not known before the end of the stream.
+func (r *Render) streamEncoding(ctx *fasthttp.RequestCtx) string {
+	if !r.opt.Gzip {
+		return ""
+	}
+	return NegotiateEncoding(string(ctx.Request.Header.Peek(AcceptEncoding)), r.opt.CompressEncodings)
+}
+
+// compress compresses the rendered response, look CompressResponse.
+func (r *Render) compress(ctx *fasthttp.RequestCtx) error {
+	return CompressResponse(ctx, r.opt.CompressEncodings, r.opt.CompressMinLength)
+}
@@ -0,0 +1,205 @@
+package render
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/gzip"
+	"github.com/valyala/fasthttp"
+)
+
+// decompress returns the decompressed data of the encoding
+func decompress(t *testing.T, encoding string, data []byte) string {
+	t.Helper()
+	var r io.Reader
+	switch encoding {
+	case EncodingGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = gr
+	case EncodingDeflate:
+		r = flate.NewReader(bytes.NewReader(data))
+	case EncodingBrotli:
+		r = brotli.NewReader(bytes.NewReader(data))
+	default:
+		return string(data)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("%s: %v", encoding, err)
+	}
+	return string(b)
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		expected       string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", EncodingGzip},
+		{"deflate, gzip", EncodingGzip},
+		{"gzip, deflate, br", EncodingBrotli},
+		{"br;q=0.5, gzip;q=0.8", EncodingGzip},
+		{"GZIP;Q=0.3, deflate;q=0.6", EncodingDeflate},
+		{"br;q=0, gzip", EncodingGzip},
+		{"*", EncodingBrotli},
+		{"*;q=0.5, gzip", EncodingGzip},
+		{"*, br;q=0", EncodingGzip},
+		{"gzip;q=0, *;q=0", ""},
+	}
+	for _, tt := range tests {
+		if got := NegotiateEncoding(tt.acceptEncoding, DefaultCompressEncodings); got != tt.expected {
+			t.Fatalf("%q: expected %q but got %q", tt.acceptEncoding, tt.expected, got)
+		}
+	}
+
+	if got := NegotiateEncoding("br, gzip", []string{EncodingGzip}); got != EncodingGzip {
+		t.Fatalf("expected only the offered encodings but got %q", got)
+	}
+}
+
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{ContentProblemJSON, ContentJSON, ContentProblemXML, ContentXML}
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		{"", ""},
+		{"text/html", ""},
+		{"application/json", ContentJSON},
+		{"*/*", ContentProblemJSON},
+		{"text/xml", ContentXML},
+		{"application/*;q=0.5, text/xml", ContentXML},
+		{"application/json;q=0.2, application/problem+xml", ContentProblemXML},
+		{"application/*, application/problem+json;q=0", ContentJSON},
+	}
+	for _, tt := range tests {
+		if got := NegotiateContentType(tt.accept, offers); got != tt.expected {
+			t.Fatalf("%q: expected %q but got %q", tt.accept, tt.expected, got)
+		}
+	}
+}
+
+func TestAddVary(t *testing.T) {
+	var h fasthttp.ResponseHeader
+	AddVary(&h, AcceptEncoding)
+	AddVary(&h, "accept-encoding")
+	AddVary(&h, Accept)
+	if vary := string(h.Peek(Vary)); vary != "Accept-Encoding, Accept" {
+		t.Fatalf("unexpected Vary %q", vary)
+	}
+
+	h.Set(Vary, "*")
+	AddVary(&h, AcceptEncoding)
+	if vary := string(h.Peek(Vary)); vary != "*" {
+		t.Fatalf("unexpected Vary %q", vary)
+	}
+}
+
+func TestCompress(t *testing.T) {
+	data := strings.Repeat("iris ", 100)
+	for _, encoding := range DefaultCompressEncodings {
+		// twice, the second time the writer comes from the pool
+		for i := 0; i < 2; i++ {
+			var buf bytes.Buffer
+			if err := Compress(&buf, encoding, []byte(data)); err != nil {
+				t.Fatalf("%s: %v", encoding, err)
+			}
+			if buf.Len() >= len(data) {
+				t.Fatalf("%s: expected the data to be compressed", encoding)
+			}
+			if got := decompress(t, encoding, buf.Bytes()); got != data {
+				t.Fatalf("%s: unexpected decompressed data %q", encoding, got)
+			}
+		}
+	}
+
+	if err := Compress(ioutil.Discard, "zstd", []byte(data)); err == nil {
+		t.Fatal("expected an error for an unsupported encoding")
+	}
+}
+
+func TestCompressResponse(t *testing.T) {
+	large := strings.Repeat("a", DefaultCompressMinLength)
+	tests := []struct {
+		acceptEncoding  string
+		body            string
+		contentEncoding string
+		contentRange    string
+		expected        string
+	}{
+		{"gzip, deflate, br", large, "", "", EncodingBrotli},
+		{"gzip", large, "", "", EncodingGzip},
+		{"deflate", large, "", "", EncodingDeflate},
+		{"", large, "", "", ""},
+		// too small
+		{"gzip", "small", "", "", ""},
+		// already encoded
+		{"gzip", large, EncodingDeflate, "", EncodingDeflate},
+		// partial
+		{"gzip", large, "", "bytes 0-1023/2048", ""},
+	}
+
+	for _, tt := range tests {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.Set(AcceptEncoding, tt.acceptEncoding)
+		ctx.Response.SetBodyString(tt.body)
+		if tt.contentEncoding != "" {
+			ctx.Response.Header.Set(ContentEncoding, tt.contentEncoding)
+		}
+		if tt.contentRange != "" {
+			ctx.Response.Header.Set("Content-Range", tt.contentRange)
+		}
+
+		if err := CompressResponse(ctx, DefaultCompressEncodings, DefaultCompressMinLength); err != nil {
+			t.Fatal(err)
+		}
+		if vary := string(ctx.Response.Header.Peek(Vary)); vary != AcceptEncoding {
+			t.Fatalf("%q: expected the Vary header to be set but got %q", tt.acceptEncoding, vary)
+		}
+		encoding := string(ctx.Response.Header.Peek(ContentEncoding))
+		if encoding != tt.expected {
+			t.Fatalf("%q: expected the encoding %q but got %q", tt.acceptEncoding, tt.expected, encoding)
+		}
+		if tt.contentEncoding == "" {
+			if got := decompress(t, encoding, ctx.Response.Body()); got != tt.body {
+				t.Fatalf("%q: unexpected body", tt.acceptEncoding)
+			}
+		}
+	}
+}
+
+func TestRenderCompress(t *testing.T) {
+	r := New(Options{Gzip: true, CompressEncodings: []string{EncodingGzip, EncodingDeflate}, CompressMinLength: 10})
+	value := map[string]string{"message": strings.Repeat("hello ", 10)}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.Set(AcceptEncoding, "br, deflate")
+	if err := r.JSON(ctx, fasthttp.StatusOK, value); err != nil {
+		t.Fatal(err)
+	}
+	if encoding := string(ctx.Response.Header.Peek(ContentEncoding)); encoding != EncodingDeflate {
+		t.Fatalf("expected the encoding %q but got %q", EncodingDeflate, encoding)
+	}
+	if got := decompress(t, EncodingDeflate, ctx.Response.Body()); !strings.HasPrefix(got, `{"message":"hello hello`) {
+		t.Fatalf("unexpected body %q", got)
+	}
+
+	// without the option the response is not compressed
+	r = New(Options{})
+	ctx = new(fasthttp.RequestCtx)
+	ctx.Request.Header.Set(AcceptEncoding, "gzip")
+	r.JSON(ctx, fasthttp.StatusOK, value)
+	if encoding := ctx.Response.Header.Peek(ContentEncoding); len(encoding) > 0 {
+		t.Fatalf("unexpected encoding %q", encoding)
+	}
+}
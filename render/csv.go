@@ -0,0 +1,195 @@
+package render
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// CSV built-in renderer.
+//
+// Accepts a slice, an array or a receive channel of structs (or pointers to structs),
+// the header row is built from the exported fields, named by their `csv:"name"` tag, `csv:"-"` skips a field.
+// A [][]string is written as it is.
+// The rows are streamed to the client, so large exports are not buffered in memory.
+type CSV struct {
+	Head
+	// StreamEncoding the content encoding of the streamed body, empty for no compression
+	StreamEncoding string
+}
+
+// csvField the index and the header name of an exported struct field.
+type csvField struct {
+	index []int
+	name  string
+}
+
+var (
+	csvFieldsCache   = make(map[reflect.Type][]csvField)
+	csvFieldsCacheMu sync.RWMutex
+
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+// Render a CSV response.
+func (c CSV) Render(ctx *fasthttp.RequestCtx, v interface{}) error {
+	write, err := csvWriteFunc(v)
+	if err != nil {
+		return err
+	}
+	sw := func(w *bufio.Writer) {
+		write(w)
+	}
+	if c.StreamEncoding != "" {
+		ctx.Response.Header.Set(ContentEncoding, c.StreamEncoding)
+		sw = CompressStreamWriter(c.StreamEncoding, sw)
+	}
+	c.Head.Write(ctx)
+	SetBodyStreamWriter(ctx, sw)
+	return nil
+}
+
+// RenderGzip a CSV response using gzip compression.
+func (c CSV) RenderGzip(ctx *fasthttp.RequestCtx, v interface{}) error {
+	c.StreamEncoding = EncodingGzip
+	return c.Render(ctx, v)
+}
+
+// csvWriteFunc validates the value and returns the func which writes it as csv,
+// the checks are done before the streaming starts, so the errors can still be rendered.
+func csvWriteFunc(v interface{}) (func(io.Writer) error, error) {
+	if records, ok := v.([][]string); ok {
+		return func(w io.Writer) error {
+			cw := csv.NewWriter(w)
+			cw.WriteAll(records)
+			return cw.Error()
+		}, nil
+	}
+
+	val := reflect.Indirect(reflect.ValueOf(v))
+	kind := val.Kind()
+	if kind != reflect.Slice && kind != reflect.Array && kind != reflect.Chan {
+		return nil, ErrCSVType.Format(val.Type())
+	}
+	if kind == reflect.Chan && val.Type().ChanDir()&reflect.RecvDir == 0 {
+		return nil, ErrCSVType.Format(val.Type())
+	}
+
+	elemType := val.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, ErrCSVType.Format(val.Type())
+	}
+	fields := csvFields(elemType)
+
+	return func(w io.Writer) error {
+		cw := csv.NewWriter(w)
+		record := make([]string, len(fields))
+		for i, f := range fields {
+			record[i] = f.name
+		}
+		cw.Write(record)
+
+		writeRow := func(item reflect.Value) {
+			item = reflect.Indirect(item)
+			for i, f := range fields {
+				record[i] = ""
+				if item.IsValid() {
+					record[i] = csvFormat(item.FieldByIndex(f.index))
+				}
+			}
+			cw.Write(record)
+		}
+
+		if kind == reflect.Chan {
+			for {
+				item, ok := val.Recv()
+				if !ok {
+					break
+				}
+				writeRow(item)
+			}
+		} else {
+			for i, n := 0, val.Len(); i < n; i++ {
+				writeRow(val.Index(i))
+			}
+		}
+
+		cw.Flush()
+		return cw.Error()
+	}, nil
+}
+
+// csvFields returns the exported fields of a struct type, the embedded structs are flattened.
+func csvFields(typ reflect.Type) []csvField {
+	csvFieldsCacheMu.RLock()
+	fields, ok := csvFieldsCache[typ]
+	csvFieldsCacheMu.RUnlock()
+	if ok {
+		return fields
+	}
+
+	fields = appendCSVFields(nil, typ, nil)
+
+	csvFieldsCacheMu.Lock()
+	csvFieldsCache[typ] = fields
+	csvFieldsCacheMu.Unlock()
+	return fields
+}
+
+func appendCSVFields(fields []csvField, typ reflect.Type, index []int) []csvField {
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag := sf.Tag.Get("csv")
+		embedded := sf.Anonymous && tag == "" && sf.Type.Kind() == reflect.Struct
+		// the exported fields of an unexported embedded struct are promoted, like the encoding/json does
+		if sf.PkgPath != "" && !embedded || tag == "-" { // unexported or skipped
+			continue
+		}
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		if embedded {
+			fields = appendCSVFields(fields, sf.Type, fieldIndex)
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, csvField{index: fieldIndex, name: name})
+	}
+	return fields
+}
+
+// csvFormat returns the string value of a csv column.
+func csvFormat(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339)
+	}
+	if v.Type().Implements(textMarshalerType) {
+		if text, err := v.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
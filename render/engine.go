@@ -4,16 +4,19 @@ import (
 	"bytes"
 	"encoding/json"
 	"encoding/xml"
-	"html/template"
+	"io"
 
-	"github.com/klauspost/compress/gzip"
+	"github.com/golang/protobuf/proto"
 	"github.com/valyala/fasthttp"
+	"gopkg.in/vmihailenco/msgpack.v2"
+	"gopkg.in/yaml.v2"
 )
 
 // Engine is the generic interface for all responses.
 type Engine interface {
 	Render(*fasthttp.RequestCtx, interface{}) error
-	//used only if config gzip is enabled
+	// RenderGzip renders and gzips the response, regardless of the client's Accept-Encoding.
+	// The Render.Render negotiates the encoding itself when compression is enabled.
 	RenderGzip(*fasthttp.RequestCtx, interface{}) error
 }
 
@@ -31,8 +34,16 @@ type Data struct {
 // HTML built-in renderer.
 type HTML struct {
 	Head
-	Name      string
-	Templates *template.Template
+	Name   string
+	Layout string
+	Engine TemplateEngine
+	// Funcs the per-request template funcs, they are used if the Engine is a TemplateFuncsEngine, look SetRequestFunc
+	Funcs map[string]interface{}
+	// StreamHeadSize if it's greater than zero the template is executed straight into the body stream,
+	// after the first StreamHeadSize bytes, look Options.StreamingHTML
+	StreamHeadSize int
+	// StreamEncoding the content encoding of the streamed body, empty for no compression
+	StreamEncoding string
 }
 
 // JSON built-in renderer.
@@ -51,6 +62,27 @@ type JSONP struct {
 	Callback string
 }
 
+// YAML built-in renderer.
+type YAML struct {
+	Head
+}
+
+// MsgPack built-in renderer.
+type MsgPack struct {
+	Head
+}
+
+// Protobuf built-in renderer, renders a proto.Message.
+type Protobuf struct {
+	Head
+}
+
+// Markdown built-in renderer, converts markdown to html.
+type Markdown struct {
+	Head
+	Sanitize bool
+}
+
 // Text built-in renderer.
 type Text struct {
 	Head
@@ -85,52 +117,56 @@ func (d Data) Render(ctx *fasthttp.RequestCtx, v interface{}) error {
 
 // RenderGzip a data response using gzip compression.
 func (d Data) RenderGzip(ctx *fasthttp.RequestCtx, v interface{}) error {
-	c := string(ctx.Request.Header.Peek(ContentType))
-	if c != "" {
-		d.Head.ContentType = c
-	}
-
-	d.Head.Write(ctx)
-	_, err := fasthttp.WriteGzip(ctx.Response.BodyWriter(), v.([]byte))
-	if err == nil {
-		ctx.Response.Header.Add("Content-Encoding", "gzip")
+	if err := d.Render(ctx, v); err != nil {
+		return err
 	}
-	return err
+	return compressBody(ctx, EncodingGzip)
 }
 
 // Render a HTML response.
 func (h HTML) Render(ctx *fasthttp.RequestCtx, binding interface{}) error {
+	if h.StreamHeadSize > 0 {
+		return h.renderStream(ctx, binding)
+	}
+
 	// Retrieve a buffer from the pool to write to.
 	out := bufPool.Get()
-	err := h.Templates.ExecuteTemplate(out, h.Name, binding)
+	err := h.execute(out, binding)
 	if err != nil {
 		bufPool.Put(out)
 		return err
 	}
 	w := ctx.Response.BodyWriter()
 	h.Head.Write(ctx)
+	// the WriteTo drains the buffer, its length is kept for the pool
+	n := out.Len()
 	out.WriteTo(w)
 
 	// Return the buffer to the pool.
-	bufPool.Put(out)
+	bufPool.PutLen(out, n)
 	return nil
 }
 
+// execute executes the template, with the per-request funcs if the engine supports them.
+func (h HTML) execute(w io.Writer, binding interface{}) error {
+	if len(h.Funcs) > 0 {
+		if engine, ok := h.Engine.(TemplateFuncsEngine); ok {
+			return engine.ExecuteWithFuncs(w, h.Name, binding, h.Layout, h.Funcs)
+		}
+	}
+	return h.Engine.Execute(w, h.Name, binding, h.Layout)
+}
+
 // RenderGzip a HTML response using Gzip compression.
 func (h HTML) RenderGzip(ctx *fasthttp.RequestCtx, binding interface{}) error {
-
-	// Retrieve a buffer from the pool to write to.
-	out := gzip.NewWriter(ctx.Response.BodyWriter())
-	err := h.Templates.ExecuteTemplate(out, h.Name, binding)
-	if err != nil {
+	h.StreamEncoding = EncodingGzip
+	if err := h.Render(ctx, binding); err != nil {
 		return err
 	}
-	//out.Flush()
-	out.Close()
-	ctx.Response.Header.Add("Content-Encoding", "gzip")
-	h.Head.Write(ctx)
-
-	return nil
+	if ctx.Response.IsBodyStream() {
+		return nil // it's compressed while it's streamed
+	}
+	return compressBody(ctx, EncodingGzip)
 }
 
 // Render a JSON response.
@@ -170,39 +206,10 @@ func (j JSON) Render(ctx *fasthttp.RequestCtx, v interface{}) error {
 
 // RenderGzip a JSON response using gzip compression.
 func (j JSON) RenderGzip(ctx *fasthttp.RequestCtx, v interface{}) error {
-	if j.StreamingJSON {
-		return j.renderStreamingJSONGzip(ctx, v)
-	}
-
-	var result []byte
-	var err error
-
-	if j.Indent {
-		result, err = json.MarshalIndent(v, "", "  ")
-		result = append(result, '\n')
-	} else {
-		result, err = json.Marshal(v)
-	}
-	if err != nil {
+	if err := j.Render(ctx, v); err != nil {
 		return err
 	}
-	ctx.Response.Header.Add("Content-Encoding", "gzip")
-
-	// Unescape HTML if needed.
-	if j.UnEscapeHTML {
-		result = bytes.Replace(result, []byte("\\u003c"), []byte("<"), -1)
-		result = bytes.Replace(result, []byte("\\u003e"), []byte(">"), -1)
-		result = bytes.Replace(result, []byte("\\u0026"), []byte("&"), -1)
-	}
-	w := gzip.NewWriter(ctx.Response.BodyWriter())
-	// JSON marshaled fine, write out the result.
-	j.Head.Write(ctx)
-	if len(j.Prefix) > 0 {
-		w.Write(j.Prefix)
-	}
-	w.Write(result)
-	w.Close()
-	return nil
+	return compressBody(ctx, EncodingGzip)
 }
 
 func (j JSON) renderStreamingJSON(ctx *fasthttp.RequestCtx, v interface{}) error {
@@ -214,17 +221,6 @@ func (j JSON) renderStreamingJSON(ctx *fasthttp.RequestCtx, v interface{}) error
 	return json.NewEncoder(w).Encode(v)
 }
 
-func (j JSON) renderStreamingJSONGzip(ctx *fasthttp.RequestCtx, v interface{}) error {
-	ctx.Response.Header.Add("Content-Encoding", "gzip")
-	j.Head.Write(ctx)
-	w := gzip.NewWriter(ctx.Response.BodyWriter())
-	if len(j.Prefix) > 0 {
-		w.Write(j.Prefix)
-	}
-	w.Close()
-	return json.NewEncoder(w).Encode(v)
-}
-
 // Render a JSONP response.
 func (j JSONP) Render(ctx *fasthttp.RequestCtx, v interface{}) error {
 	var result []byte
@@ -255,32 +251,25 @@ func (j JSONP) Render(ctx *fasthttp.RequestCtx, v interface{}) error {
 
 // RenderGzip a JSONP response using gzip compression.
 func (j JSONP) RenderGzip(ctx *fasthttp.RequestCtx, v interface{}) error {
-	var result []byte
-	var err error
-
-	if j.Indent {
-		result, err = json.MarshalIndent(v, "", "  ")
-	} else {
-		result, err = json.Marshal(v)
-	}
-	if err != nil {
+	if err := j.Render(ctx, v); err != nil {
 		return err
 	}
-	w := gzip.NewWriter(ctx.Response.BodyWriter())
+	return compressBody(ctx, EncodingGzip)
+}
 
-	ctx.Response.Header.Add("Content-Encoding", "gzip")
-	// JSON marshaled fine, write out the result.
-	j.Head.Write(ctx)
-	w.Write([]byte(j.Callback + "("))
-	w.Write(result)
-	w.Write([]byte(");"))
+// Render a markdown response, as html.
+func (m Markdown) Render(ctx *fasthttp.RequestCtx, v interface{}) error {
+	m.Head.Write(ctx)
+	ctx.Response.BodyWriter().Write(MarkdownToHTML(v.([]byte), m.Sanitize))
+	return nil
+}
 
-	// If indenting, append a new line.
-	if j.Indent {
-		w.Write([]byte("\n"))
+// RenderGzip a markdown response using gzip compression.
+func (m Markdown) RenderGzip(ctx *fasthttp.RequestCtx, v interface{}) error {
+	if err := m.Render(ctx, v); err != nil {
+		return err
 	}
-	w.Close()
-	return nil
+	return compressBody(ctx, EncodingGzip)
 }
 
 // Render a text response.
@@ -297,15 +286,10 @@ func (t Text) Render(ctx *fasthttp.RequestCtx, v interface{}) error {
 
 // RenderGzip a Text response using gzip compression.
 func (t Text) RenderGzip(ctx *fasthttp.RequestCtx, v interface{}) error {
-	c := string(ctx.Request.Header.Peek(ContentType))
-	if c != "" {
-		t.Head.ContentType = c
+	if err := t.Render(ctx, v); err != nil {
+		return err
 	}
-	ctx.Response.Header.Add("Content-Encoding", "gzip")
-	t.Head.Write(ctx)
-	fasthttp.WriteGzip(ctx.Response.BodyWriter(), []byte(v.(string)))
-
-	return nil
+	return compressBody(ctx, EncodingGzip)
 }
 
 // Render an XML response.
@@ -335,26 +319,69 @@ func (x XML) Render(ctx *fasthttp.RequestCtx, v interface{}) error {
 
 // RenderGzip an XML response using gzip compression.
 func (x XML) RenderGzip(ctx *fasthttp.RequestCtx, v interface{}) error {
-	var result []byte
-	var err error
+	if err := x.Render(ctx, v); err != nil {
+		return err
+	}
+	return compressBody(ctx, EncodingGzip)
+}
 
-	if x.Indent {
-		result, err = xml.MarshalIndent(v, "", "  ")
-		result = append(result, '\n')
-	} else {
-		result, err = xml.Marshal(v)
+// Render a YAML response.
+func (y YAML) Render(ctx *fasthttp.RequestCtx, v interface{}) error {
+	result, err := yaml.Marshal(v)
+	if err != nil {
+		return err
 	}
+	y.Head.Write(ctx)
+	ctx.Response.BodyWriter().Write(result)
+	return nil
+}
+
+// RenderGzip a YAML response using gzip compression.
+func (y YAML) RenderGzip(ctx *fasthttp.RequestCtx, v interface{}) error {
+	if err := y.Render(ctx, v); err != nil {
+		return err
+	}
+	return compressBody(ctx, EncodingGzip)
+}
+
+// Render a MessagePack response.
+func (m MsgPack) Render(ctx *fasthttp.RequestCtx, v interface{}) error {
+	result, err := msgpack.Marshal(v)
 	if err != nil {
 		return err
 	}
-	ctx.Response.Header.Add("Content-Encoding", "gzip")
-	// XML marshaled fine, write out the result.
-	x.Head.Write(ctx)
-	w := gzip.NewWriter(ctx.Response.BodyWriter())
-	if len(x.Prefix) > 0 {
-		w.Write(x.Prefix)
+	m.Head.Write(ctx)
+	ctx.Response.BodyWriter().Write(result)
+	return nil
+}
+
+// RenderGzip a MessagePack response using gzip compression.
+func (m MsgPack) RenderGzip(ctx *fasthttp.RequestCtx, v interface{}) error {
+	if err := m.Render(ctx, v); err != nil {
+		return err
 	}
-	w.Write(result)
-	w.Close()
+	return compressBody(ctx, EncodingGzip)
+}
+
+// Render a Protobuf response.
+func (p Protobuf) Render(ctx *fasthttp.RequestCtx, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return ErrProtobufType.Format(v)
+	}
+	result, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	p.Head.Write(ctx)
+	ctx.Response.BodyWriter().Write(result)
 	return nil
 }
+
+// RenderGzip a Protobuf response using gzip compression.
+func (p Protobuf) RenderGzip(ctx *fasthttp.RequestCtx, v interface{}) error {
+	if err := p.Render(ctx, v); err != nil {
+		return err
+	}
+	return compressBody(ctx, EncodingGzip)
+}
@@ -0,0 +1,207 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/valyala/fasthttp"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+// responseBody returns the body of the response, a body stream is read until its end
+func responseBody(t *testing.T, ctx *fasthttp.RequestCtx) []byte {
+	t.Helper()
+	var body bytes.Buffer
+	if err := ctx.Response.BodyWriteTo(&body); err != nil {
+		t.Fatal(err)
+	}
+	return body.Bytes()
+}
+
+type csvBase struct {
+	ID int `csv:"id"`
+}
+
+type csvUser struct {
+	csvBase
+	Name     string    `csv:"name"`
+	Password string    `csv:"-"`
+	Created  time.Time `csv:"created"`
+	Nick     *string
+	hidden   string
+}
+
+func TestCSV(t *testing.T) {
+	created := time.Date(2016, 6, 1, 12, 0, 0, 0, time.UTC)
+	nick := "k"
+	users := []*csvUser{
+		{csvBase: csvBase{ID: 1}, Name: "kataras", Password: "secret", Created: created, Nick: &nick},
+		{csvBase: csvBase{ID: 2}, Name: "comma, \"quoted\"", Created: created},
+		nil,
+	}
+	expected := "id,name,created,Nick\n" +
+		"1,kataras,2016-06-01T12:00:00Z,k\n" +
+		"2,\"comma, \"\"quoted\"\"\",2016-06-01T12:00:00Z,\n" +
+		",,,\n"
+
+	r := New()
+	ctx := new(fasthttp.RequestCtx)
+	if err := r.CSV(ctx, fasthttp.StatusOK, users); err != nil {
+		t.Fatal(err)
+	}
+	if !ctx.Response.IsBodyStream() {
+		t.Fatal("expected the rows to be streamed")
+	}
+	if body := string(responseBody(t, ctx)); body != expected {
+		t.Fatalf("unexpected csv %q", body)
+	}
+	if contentType := string(ctx.Response.Header.ContentType()); contentType != "text/csv; charset=UTF-8" {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+
+	// a channel
+	ch := make(chan csvUser, 1)
+	ch <- csvUser{csvBase: csvBase{ID: 3}, Name: "chan"}
+	close(ch)
+	ctx = new(fasthttp.RequestCtx)
+	r.CSV(ctx, fasthttp.StatusOK, ch)
+	if body := string(responseBody(t, ctx)); body != "id,name,created,Nick\n3,chan,0001-01-01T00:00:00Z,\n" {
+		t.Fatalf("unexpected csv of a channel %q", body)
+	}
+
+	// the records
+	ctx = new(fasthttp.RequestCtx)
+	r.CSV(ctx, fasthttp.StatusOK, [][]string{{"a", "b"}, {"1", "2"}})
+	if body := string(responseBody(t, ctx)); body != "a,b\n1,2\n" {
+		t.Fatalf("unexpected csv of records %q", body)
+	}
+
+	// the invalid values are rendered as errors, before the streaming
+	for _, v := range []interface{}{42, []int{1}, make(chan<- csvUser)} {
+		ctx = new(fasthttp.RequestCtx)
+		if err := r.CSV(ctx, fasthttp.StatusOK, v); err == nil {
+			t.Fatalf("%T: expected an error", v)
+		}
+		if status := ctx.Response.StatusCode(); status != fasthttp.StatusInternalServerError {
+			t.Fatalf("%T: expected status code %d but got %d", v, fasthttp.StatusInternalServerError, status)
+		}
+	}
+}
+
+func TestStreamCompress(t *testing.T) {
+	r := New(Options{Gzip: true})
+	rows := make([][]string, 100)
+	for i := range rows {
+		rows[i] = []string{"value"}
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.Set(AcceptEncoding, "deflate")
+	r.CSV(ctx, fasthttp.StatusOK, rows)
+	if encoding := string(ctx.Response.Header.Peek(ContentEncoding)); encoding != EncodingDeflate {
+		t.Fatalf("expected the csv to be compressed with %q but got %q", EncodingDeflate, encoding)
+	}
+	if body := decompress(t, EncodingDeflate, responseBody(t, ctx)); body != strings.Repeat("value\n", 100) {
+		t.Fatalf("unexpected csv %q", body)
+	}
+
+	ch := make(chan interface{}, 2)
+	ch <- 1
+	ch <- 2
+	close(ch)
+	ctx = new(fasthttp.RequestCtx)
+	ctx.Request.Header.Set(AcceptEncoding, "gzip")
+	r.JSONStream(ctx, fasthttp.StatusOK, ch)
+	if encoding := string(ctx.Response.Header.Peek(ContentEncoding)); encoding != EncodingGzip {
+		t.Fatalf("expected the json stream to be compressed with %q but got %q", EncodingGzip, encoding)
+	}
+	if body := decompress(t, EncodingGzip, responseBody(t, ctx)); body != "1\n2\n" {
+		t.Fatalf("unexpected json stream %q", body)
+	}
+	if vary := string(ctx.Response.Header.Peek(Vary)); vary != AcceptEncoding {
+		t.Fatalf("expected the Vary header but got %q", vary)
+	}
+
+	// the client doesn't accept any encoding
+	ctx = new(fasthttp.RequestCtx)
+	r.CSV(ctx, fasthttp.StatusOK, [][]string{{"plain"}})
+	if encoding := ctx.Response.Header.Peek(ContentEncoding); len(encoding) > 0 {
+		t.Fatalf("unexpected encoding %q", encoding)
+	}
+	if body := string(responseBody(t, ctx)); body != "plain\n" {
+		t.Fatalf("unexpected csv %q", body)
+	}
+
+	// the RenderGzip of the streams
+	ctx = new(fasthttp.RequestCtx)
+	CSV{Head: Head{ContentType: ContentCSV, Status: fasthttp.StatusOK}}.RenderGzip(ctx, [][]string{{"gzip"}})
+	if body := decompress(t, string(ctx.Response.Header.Peek(ContentEncoding)), responseBody(t, ctx)); body != "gzip\n" {
+		t.Fatalf("unexpected csv of the RenderGzip %q", body)
+	}
+}
+
+func TestYAML(t *testing.T) {
+	r := New()
+	ctx := new(fasthttp.RequestCtx)
+	if err := r.YAML(ctx, fasthttp.StatusCreated, map[string]interface{}{"name": "iris", "tags": []string{"go", "web"}}); err != nil {
+		t.Fatal(err)
+	}
+	if body := string(ctx.Response.Body()); body != "name: iris\ntags:\n- go\n- web\n" {
+		t.Fatalf("unexpected yaml %q", body)
+	}
+	if status := ctx.Response.StatusCode(); status != fasthttp.StatusCreated {
+		t.Fatalf("unexpected status code %d", status)
+	}
+	if contentType := string(ctx.Response.Header.ContentType()); contentType != "application/x-yaml; charset=UTF-8" {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+}
+
+func TestMsgPack(t *testing.T) {
+	type message struct {
+		Name string
+		Age  int
+	}
+	r := New()
+	ctx := new(fasthttp.RequestCtx)
+	if err := r.MsgPack(ctx, fasthttp.StatusOK, message{Name: "iris", Age: 3}); err != nil {
+		t.Fatal(err)
+	}
+	var got message
+	if err := msgpack.Unmarshal(ctx.Response.Body(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "iris" || got.Age != 3 {
+		t.Fatalf("unexpected message %+v", got)
+	}
+	if contentType := string(ctx.Response.Header.ContentType()); contentType != ContentMsgPack {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+}
+
+func TestProtobuf(t *testing.T) {
+	r := New()
+	ctx := new(fasthttp.RequestCtx)
+	if err := r.Protobuf(ctx, fasthttp.StatusOK, &wrappers.StringValue{Value: "iris"}); err != nil {
+		t.Fatal(err)
+	}
+	var got wrappers.StringValue
+	if err := proto.Unmarshal(ctx.Response.Body(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Value != "iris" {
+		t.Fatalf("unexpected message %v", got.Value)
+	}
+	if contentType := string(ctx.Response.Header.ContentType()); contentType != ContentProtobuf {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+
+	ctx = new(fasthttp.RequestCtx)
+	if err := r.Render(ctx, Protobuf{Head: Head{ContentType: ContentProtobuf, Status: fasthttp.StatusOK}}, "not a message"); err == nil {
+		t.Fatal("expected an error for a value which is not a proto.Message")
+	}
+}
@@ -0,0 +1,18 @@
+package render
+
+import "github.com/kataras/iris/errors"
+
+var (
+	// ErrTemplateNotFound returns an error with message: 'Template '+template name' doesn't exists'
+	ErrTemplateNotFound = errors.New("Template '%s' doesn't exists")
+	// ErrUnsupportedEncoding returns an error with message: 'Content encoding '+encoding' is not supported'
+	ErrUnsupportedEncoding = errors.New("Content encoding '%s' is not supported")
+	// ErrCSVType returns an error with message: 'CSV can't render '+type', accepts slices, arrays or channels of structs and [][]string'
+	ErrCSVType = errors.New("CSV can't render %s, accepts slices, arrays or channels of structs and [][]string")
+	// ErrProtobufType returns an error with message: 'Protobuf can't render '+value', it's not a proto.Message'
+	ErrProtobufType = errors.New("Protobuf can't render %T, it's not a proto.Message")
+	// ErrJSONStreamType returns an error with message: 'JSONStream can't stream '+type', accepts <-chan interface{} and func() (interface{}, bool)'
+	ErrJSONStreamType = errors.New("JSONStream can't stream %T, accepts <-chan interface{} and func() (interface{}, bool)")
+	// ErrFrontMatter returns an error with message: 'Front matter couldn't be parsed, reason: '+reason'
+	ErrFrontMatter = errors.New("Front matter couldn't be parsed, reason: %s")
+)
@@ -0,0 +1,122 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+
+	"github.com/valyala/fasthttp"
+)
+
+// DefaultStreamingHTMLHeadSize the default size of the head buffer of the streaming templates.
+const DefaultStreamingHTMLHeadSize = 4096
+
+// htmlStreamWriter is the writer of a streaming template execution,
+// it keeps the first bytes into the head buffer and, when the head is full, it continues to the writer of the body stream.
+type htmlStreamWriter struct {
+	head     *bytes.Buffer
+	headSize int
+	// ready is closed when the head is full or when the execution is finished before that
+	ready chan struct{}
+	// streaming is true after the head is full, look ready
+	streaming bool
+	// err the execution's error, if it's finished before the head is full
+	err error
+
+	// out receives the writer of the body stream's callback, the execution continues there
+	out chan *bufio.Writer
+	// done is closed when the execution is finished, after the head is full, so the callback can return
+	done chan struct{}
+	w    *bufio.Writer
+}
+
+func (w *htmlStreamWriter) Write(p []byte) (int, error) {
+	if !w.streaming {
+		if w.head.Len()+len(p) <= w.headSize {
+			return w.head.Write(p)
+		}
+		if err := w.startStreaming(); err != nil {
+			return 0, err
+		}
+	}
+	return w.w.Write(p)
+}
+
+// startStreaming lets the handler return with the body stream and waits for its callback's writer, then writes the head to it.
+func (w *htmlStreamWriter) startStreaming() error {
+	w.streaming = true
+	close(w.ready)
+
+	w.w = <-w.out
+	_, err := w.w.Write(w.head.Bytes())
+	bufPool.Put(w.head)
+	w.head = nil
+	return err
+}
+
+// finish is called when the execution is finished.
+func (w *htmlStreamWriter) finish(err error) {
+	if !w.streaming {
+		w.err = err
+		close(w.ready)
+		return
+	}
+	// a late error can't change the response anymore, the client sees a truncated body
+	close(w.done)
+}
+
+// streamBody is the callback of the body stream, the rest of the execution writes to its writer, it returns when the execution is finished.
+// When the client disconnects the writes fail, so the execution stops with an error at its next write.
+func (w *htmlStreamWriter) streamBody(bw *bufio.Writer) {
+	w.out <- bw
+	<-w.done
+}
+
+// renderStream executes the template straight into the response's body stream,
+// the first StreamHeadSize bytes are buffered, so the execution errors which happen before that are rendered as usual.
+// If the StreamEncoding is not empty the streamed body is compressed with it.
+//
+// The execution continues, inside the body stream's callback, after the handler is returned and the iris.Context is reused by another request,
+// so the binding and the per-request funcs should not keep the Context, take the values they need from it before the render,
+// the map of the per-request funcs is copied.
+func (h HTML) renderStream(ctx *fasthttp.RequestCtx, binding interface{}) error {
+	if len(h.Funcs) > 0 {
+		funcs := make(map[string]interface{}, len(h.Funcs))
+		for k, v := range h.Funcs {
+			funcs[k] = v
+		}
+		h.Funcs = funcs
+	}
+
+	w := &htmlStreamWriter{
+		head:     bufPool.Get(),
+		headSize: h.StreamHeadSize,
+		ready:    make(chan struct{}),
+		out:      make(chan *bufio.Writer),
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		w.finish(h.execute(w, binding))
+	}()
+
+	<-w.ready
+	if !w.streaming {
+		// the page is smaller than the head, it's rendered as usual
+		defer bufPool.Put(w.head)
+		if w.err != nil {
+			return w.err
+		}
+		h.Head.Write(ctx)
+		ctx.Response.BodyWriter().Write(w.head.Bytes())
+		return nil
+	}
+
+	sw := w.streamBody
+	if h.StreamEncoding != "" {
+		ctx.Response.Header.Set(ContentEncoding, h.StreamEncoding)
+		sw = CompressStreamWriter(h.StreamEncoding, sw)
+	}
+	h.Head.Write(ctx)
+	SetBodyStreamWriter(ctx, sw)
+	return nil
+}
@@ -0,0 +1,90 @@
+package render
+
+import (
+	"errors"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func newStreamTestRender(options Options) *Render {
+	files := map[string][]byte{
+		"page.html": []byte(`{{ range . }}{{ . }}{{ end }}`),
+		"fail.html": []byte(`{{ range . }}{{ . }}{{ end }}{{ fail }}`),
+	}
+	options.Funcs = []template.FuncMap{{"fail": func() (string, error) { return "", errors.New("late") }}}
+	options.StreamingHTML = true
+	options.StreamingHTMLHeadSize = 16
+	return newTestRender(NewHTMLEngine(), files, options)
+}
+
+func TestStreamingHTML(t *testing.T) {
+	r := newStreamTestRender(Options{})
+	large := make([]string, 100)
+	for i := range large {
+		large[i] = "chunk"
+	}
+
+	// smaller than the head, it's rendered as usual
+	ctx := renderHTML(t, r, "page", []string{"small"})
+	if ctx.Response.IsBodyStream() {
+		t.Fatal("expected a small page not to be streamed")
+	}
+	if body := string(ctx.Response.Body()); body != "small" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	ctx = renderHTML(t, r, "page", large)
+	if !ctx.Response.IsBodyStream() {
+		t.Fatal("expected a large page to be streamed")
+	}
+	if body := string(responseBody(t, ctx)); body != strings.Repeat("chunk", 100) {
+		t.Fatalf("unexpected body %q", body)
+	}
+	if contentType := string(ctx.Response.Header.ContentType()); contentType != "text/html; charset=UTF-8" {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+
+	// an error inside the head is rendered as usual
+	ctx = new(fasthttp.RequestCtx)
+	if err := r.HTML(ctx, fasthttp.StatusOK, "fail", []string{"a"}); err == nil {
+		t.Fatal("expected the error of the head")
+	}
+	if status := ctx.Response.StatusCode(); status != fasthttp.StatusInternalServerError {
+		t.Fatalf("expected status code %d but got %d", fasthttp.StatusInternalServerError, status)
+	}
+
+	// a later error truncates the body
+	ctx = renderHTML(t, r, "fail", large)
+	if body := string(responseBody(t, ctx)); body != strings.Repeat("chunk", 100) {
+		t.Fatalf("unexpected body of a late error %q", body)
+	}
+
+	// the client is gone, the execution stops
+	ctx = renderHTML(t, r, "page", large)
+	if err := ctx.Response.BodyWriteTo(brokenWriter{}); err == nil {
+		t.Fatal("expected the write error")
+	}
+}
+
+func TestStreamingHTMLCompress(t *testing.T) {
+	r := newStreamTestRender(Options{Gzip: true})
+	large := make([]string, 100)
+	for i := range large {
+		large[i] = "chunk"
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.Set(AcceptEncoding, "gzip")
+	if err := r.HTML(ctx, fasthttp.StatusOK, "page", large); err != nil {
+		t.Fatal(err)
+	}
+	if encoding := string(ctx.Response.Header.Peek(ContentEncoding)); encoding != EncodingGzip {
+		t.Fatalf("expected the encoding %q but got %q", EncodingGzip, encoding)
+	}
+	if body := decompress(t, EncodingGzip, responseBody(t, ctx)); body != strings.Repeat("chunk", 100) {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
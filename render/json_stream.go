@@ -0,0 +1,142 @@
+package render
+
+import (
+	"bufio"
+	"encoding/json"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// ContentNDJSON header value for newline-delimited JSON data.
+	ContentNDJSON = "application/x-ndjson"
+	// DefaultJSONStreamFlushEvery the default number of values which are written before each flush of the JSONStream.
+	DefaultJSONStreamFlushEvery = 100
+)
+
+// JSONStreamOptions is a struct for specifying options for a JSONStream call.
+type JSONStreamOptions struct {
+	// Array writes the values as a JSON array, which is built incrementally, instead of newline-delimited JSON. Default is false
+	Array bool
+	// FlushEvery sends the written values to the client after this number of values,
+	// a channel's values are sent also when the channel has no value ready. Default is 100
+	FlushEvery int
+}
+
+// JSONStream built-in renderer.
+//
+// Accepts a channel of values (<-chan interface{} or chan interface{}) or an iterator func() (interface{}, bool),
+// which returns false when there are no more values, and streams them to the client as newline-delimited JSON or as a JSON array.
+// The streaming stops when the client disconnects, the remaining values of a channel are drained and dropped
+// so its producer is not blocked, use an iterator if the producer should stop too.
+type JSONStream struct {
+	Head
+	JSONStreamOptions
+	// StreamEncoding the content encoding of the streamed body, empty for no compression
+	StreamEncoding string
+}
+
+// jsonStreamSource returns the channel or the iterator of the v.
+func jsonStreamSource(v interface{}) (<-chan interface{}, func() (interface{}, bool), error) {
+	switch source := v.(type) {
+	case <-chan interface{}:
+		return source, nil, nil
+	case chan interface{}:
+		return source, nil, nil
+	case func() (interface{}, bool):
+		return nil, source, nil
+	}
+	return nil, nil, ErrJSONStreamType.Format(v)
+}
+
+// Render a newline-delimited JSON or a JSON array streaming response.
+func (j JSONStream) Render(ctx *fasthttp.RequestCtx, v interface{}) error {
+	ch, next, err := jsonStreamSource(v)
+	if err != nil {
+		return err
+	}
+	sw := func(w *bufio.Writer) {
+		j.stream(w, w.Flush, ch, next)
+	}
+	if j.StreamEncoding != "" {
+		ctx.Response.Header.Set(ContentEncoding, j.StreamEncoding)
+		sw = CompressStreamWriter(j.StreamEncoding, sw)
+	}
+	j.Head.Write(ctx)
+	SetBodyStreamWriter(ctx, sw)
+	return nil
+}
+
+// RenderGzip a newline-delimited JSON or a JSON array streaming response using gzip compression.
+func (j JSONStream) RenderGzip(ctx *fasthttp.RequestCtx, v interface{}) error {
+	j.StreamEncoding = EncodingGzip
+	return j.Render(ctx, v)
+}
+
+// stream writes the values to the w and flushes them with the flush func, until the source is exhausted or the client is gone.
+func (j JSONStream) stream(w *bufio.Writer, flush func() error, ch <-chan interface{}, next func() (interface{}, bool)) (err error) {
+	if ch != nil {
+		defer func() {
+			if err != nil {
+				// the values are no longer written, don't keep the producer blocked
+				go func() {
+					for range ch {
+					}
+				}()
+			}
+		}()
+	}
+
+	flushEvery := j.FlushEvery
+	if flushEvery <= 0 {
+		flushEvery = DefaultJSONStreamFlushEvery
+	}
+
+	if j.Array {
+		w.WriteByte('[')
+	}
+
+	for n := 0; ; n++ {
+		var value interface{}
+		var ok bool
+		if ch != nil {
+			select {
+			case value, ok = <-ch:
+			default:
+				// nothing is ready, send what we have while waiting
+				if err = flush(); err != nil {
+					return
+				}
+				value, ok = <-ch
+			}
+		} else {
+			value, ok = next()
+		}
+		if !ok {
+			break
+		}
+
+		var result []byte
+		if result, err = json.Marshal(value); err != nil {
+			return
+		}
+		if j.Array && n > 0 {
+			w.WriteByte(',')
+		}
+		w.Write(result)
+		if !j.Array {
+			w.WriteByte('\n')
+		}
+
+		if (n+1)%flushEvery == 0 {
+			if err = flush(); err != nil {
+				return
+			}
+		}
+	}
+
+	if j.Array {
+		w.WriteByte(']')
+	}
+	return flush()
+}
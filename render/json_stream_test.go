@@ -0,0 +1,111 @@
+package render
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestJSONStream(t *testing.T) {
+	r := New()
+	values := func() chan interface{} {
+		ch := make(chan interface{})
+		go func() {
+			ch <- map[string]int{"a": 1}
+			ch <- "two"
+			ch <- 3
+			close(ch)
+		}()
+		return ch
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	if err := r.JSONStream(ctx, fasthttp.StatusOK, values()); err != nil {
+		t.Fatal(err)
+	}
+	if body := string(responseBody(t, ctx)); body != "{\"a\":1}\n\"two\"\n3\n" {
+		t.Fatalf("unexpected ndjson %q", body)
+	}
+	if contentType := string(ctx.Response.Header.ContentType()); contentType != "application/x-ndjson; charset=UTF-8" {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+
+	ctx = new(fasthttp.RequestCtx)
+	r.JSONStream(ctx, fasthttp.StatusOK, values(), JSONStreamOptions{Array: true, FlushEvery: 1})
+	if body := string(responseBody(t, ctx)); body != `[{"a":1},"two",3]` {
+		t.Fatalf("unexpected json array %q", body)
+	}
+	if contentType := string(ctx.Response.Header.ContentType()); contentType != "application/json; charset=UTF-8" {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+
+	// an empty array
+	empty := make(chan interface{})
+	close(empty)
+	ctx = new(fasthttp.RequestCtx)
+	r.JSONStream(ctx, fasthttp.StatusOK, empty, JSONStreamOptions{Array: true})
+	if body := string(responseBody(t, ctx)); body != "[]" {
+		t.Fatalf("unexpected empty json array %q", body)
+	}
+}
+
+func TestJSONStreamFunc(t *testing.T) {
+	r := New()
+	n := 0
+	next := func() (interface{}, bool) {
+		n++
+		return n, n <= 3
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	if err := r.JSONStreamFunc(ctx, fasthttp.StatusOK, next, JSONStreamOptions{Array: true}); err != nil {
+		t.Fatal(err)
+	}
+	if body := string(responseBody(t, ctx)); body != "[1,2,3]" {
+		t.Fatalf("unexpected json array %q", body)
+	}
+
+	// the invalid sources are rendered as errors, before the streaming
+	ctx = new(fasthttp.RequestCtx)
+	if err := r.Render(ctx, JSONStream{Head: Head{ContentType: ContentNDJSON, Status: fasthttp.StatusOK}}, []int{1}); err == nil {
+		t.Fatal("expected an error for an invalid source")
+	}
+	if status := ctx.Response.StatusCode(); status != fasthttp.StatusInternalServerError {
+		t.Fatalf("expected status code %d but got %d", fasthttp.StatusInternalServerError, status)
+	}
+}
+
+// brokenWriter fails all the writes, like a disconnected client
+type brokenWriter struct{}
+
+func (brokenWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestJSONStreamDisconnect(t *testing.T) {
+	r := New()
+	ch := make(chan interface{})
+	produced := make(chan struct{})
+	go func() {
+		for i := 0; i < 10000; i++ {
+			ch <- i
+		}
+		close(ch)
+		close(produced)
+	}()
+
+	ctx := new(fasthttp.RequestCtx)
+	r.JSONStream(ctx, fasthttp.StatusOK, ch, JSONStreamOptions{FlushEvery: 1})
+	if err := ctx.Response.BodyWriteTo(brokenWriter{}); err == nil {
+		t.Fatal("expected the write error")
+	}
+
+	// the remaining values are drained, so the producer is not blocked
+	select {
+	case <-produced:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the producer is blocked after the client is gone")
+	}
+}
@@ -0,0 +1,67 @@
+package render
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/russross/blackfriday"
+	"gopkg.in/yaml.v2"
+)
+
+// frontMatterDelim the line which opens and closes the yaml front matter of a markdown file.
+var frontMatterDelim = []byte("---")
+
+// MarkdownToHTML converts the markdown source to html,
+// if sanitize is true the result is cleaned from any unsafe html (scripts, event attributes and such) which the source may contain.
+func MarkdownToHTML(markdown []byte, sanitize bool) []byte {
+	output := blackfriday.MarkdownCommon(markdown)
+	if sanitize {
+		output = bluemonday.UGCPolicy().SanitizeBytes(output)
+	}
+	return output
+}
+
+// ParseFrontMatter splits the yaml front matter, which is placed between two "---" lines at the top of the source,
+// from the markdown contents. If the source has no front matter then the front is empty and the markdown is the whole source.
+func ParseFrontMatter(source []byte) (map[string]interface{}, []byte, error) {
+	front := make(map[string]interface{})
+
+	// the first line should be the "---"
+	lines := bytes.SplitN(source, []byte("\n"), 2)
+	if len(lines) < 2 || !bytes.Equal(bytes.TrimSpace(lines[0]), frontMatterDelim) {
+		return front, source, nil
+	}
+	rest := lines[1]
+
+	// find the closing "---" line
+	for i := 0; i < len(rest); {
+		line, next := rest[i:], len(rest)
+		if idx := bytes.IndexByte(line, '\n'); idx >= 0 {
+			line, next = line[0:idx], i+idx+1
+		}
+		if bytes.Equal(bytes.TrimSpace(line), frontMatterDelim) {
+			if err := yaml.Unmarshal(rest[0:i], &front); err != nil {
+				return nil, nil, ErrFrontMatter.Format(err.Error())
+			}
+			return front, rest[next:], nil
+		}
+		i = next
+	}
+
+	return front, source, nil
+}
+
+// MarkdownHTML converts the markdown source to html, it's sanitized if the Options.MarkdownSanitize is true.
+func (r *Render) MarkdownHTML(markdown []byte) template.HTML {
+	return template.HTML(MarkdownToHTML(markdown, r.opt.MarkdownSanitize))
+}
+
+// markdownFuncs returns the template funcs which are registered to all template engines.
+func (r *Render) markdownFuncs() template.FuncMap {
+	return template.FuncMap{
+		"markdown": func(markdown string) template.HTML {
+			return r.MarkdownHTML([]byte(markdown))
+		},
+	}
+}
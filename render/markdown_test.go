@@ -0,0 +1,96 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	source := []byte("# Title\n\nsome *text* <script>alert(1)</script>\n")
+
+	html := string(MarkdownToHTML(source, false))
+	if !strings.Contains(html, "<h1>Title</h1>") || !strings.Contains(html, "<em>text</em>") {
+		t.Fatalf("unexpected html %q", html)
+	}
+	if !strings.Contains(html, "<script>") {
+		t.Fatalf("expected the html to be kept without the sanitize, got %q", html)
+	}
+
+	html = string(MarkdownToHTML(source, true))
+	if strings.Contains(html, "<script>") {
+		t.Fatalf("expected the script to be removed, got %q", html)
+	}
+	if !strings.Contains(html, "<em>text</em>") {
+		t.Fatalf("expected the safe html to be kept, got %q", html)
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		source   string
+		front    map[string]interface{}
+		markdown string
+	}{
+		{"# Title\n", map[string]interface{}{}, "# Title\n"},
+		{"---\ntitle: Install\nweight: 2\n---\n# Install\n", map[string]interface{}{"title": "Install", "weight": 2}, "# Install\n"},
+		{"---\r\ntitle: Windows\r\n---\r\nbody", map[string]interface{}{"title": "Windows"}, "body"},
+		// not closed, it's not a front matter
+		{"---\ntitle: Open\n", map[string]interface{}{}, "---\ntitle: Open\n"},
+		// the delimiter should be the first line
+		{"intro\n---\ntitle: No\n---\n", map[string]interface{}{}, "intro\n---\ntitle: No\n---\n"},
+	}
+
+	for _, tt := range tests {
+		front, markdown, err := ParseFrontMatter([]byte(tt.source))
+		if err != nil {
+			t.Fatalf("%q: %v", tt.source, err)
+		}
+		if string(markdown) != tt.markdown {
+			t.Fatalf("%q: expected the markdown %q but got %q", tt.source, tt.markdown, markdown)
+		}
+		if len(front) != len(tt.front) {
+			t.Fatalf("%q: expected the front matter %v but got %v", tt.source, tt.front, front)
+		}
+		for k, v := range tt.front {
+			if front[k] != v {
+				t.Fatalf("%q: expected %s to be %v but got %v", tt.source, k, v, front[k])
+			}
+		}
+	}
+
+	if _, _, err := ParseFrontMatter([]byte("---\ntitle: [broken\n---\n")); err == nil {
+		t.Fatal("expected an error for an invalid yaml front matter")
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	r := New(Options{MarkdownSanitize: true})
+	ctx := new(fasthttp.RequestCtx)
+	if err := r.Markdown(ctx, fasthttp.StatusOK, []byte("**bold**<script>x</script>")); err != nil {
+		t.Fatal(err)
+	}
+	if body := string(ctx.Response.Body()); !strings.Contains(body, "<strong>bold</strong>") || strings.Contains(body, "<script>") {
+		t.Fatalf("unexpected body %q", body)
+	}
+	if contentType := string(ctx.Response.Header.ContentType()); contentType != "text/html; charset=UTF-8" {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+
+	// the markdown template func of all the engines
+	engines := []struct {
+		engine TemplateEngine
+		source string
+	}{
+		{NewHTMLEngine(), `{{ markdown "# Hi" }}`},
+		{NewHandlebarsEngine(), `{{{markdown "# Hi"}}}`},
+		{NewPongo2Engine(), `{{ markdown("# Hi")|safe }}`},
+	}
+	for _, e := range engines {
+		r = newTestRender(e.engine, map[string][]byte{"index.html": []byte(e.source)})
+		if body := string(renderHTML(t, r, "index", nil).Response.Body()); strings.TrimSpace(body) != "<h1>Hi</h1>" {
+			t.Fatalf("%T: unexpected body %q", e.engine, body)
+		}
+	}
+}
@@ -1,15 +1,13 @@
 package render
 
 import (
-	"bytes"
-	"fmt"
 	"html/template"
-	"io/ioutil"
-	"log"
-	"os"
-	"path/filepath"
+	"net/http"
 	"strings"
+	"sync"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/kataras/iris/utils"
 	"github.com/valyala/fasthttp"
 )
 
@@ -30,8 +28,22 @@ const (
 	ContentType = "Content-Type"
 	// ContentXHTML header value for XHTML data.
 	ContentXHTML = "application/xhtml+xml"
+	// ContentMarkdown header value for the html which is converted from markdown.
+	ContentMarkdown = "text/html"
+	// ContentCSV header value for CSV data.
+	ContentCSV = "text/csv"
+	// ContentYAML header value for YAML data.
+	ContentYAML = "application/x-yaml"
+	// ContentMsgPack header value for MessagePack data.
+	ContentMsgPack = "application/x-msgpack"
+	// ContentProtobuf header value for Protobuf data.
+	ContentProtobuf = "application/x-protobuf"
 	// ContentXML header value for XML data.
 	ContentXML = "text/xml"
+	// ContentProblemJSON header value for problem details (RFC 7807) JSON data.
+	ContentProblemJSON = "application/problem+json"
+	// ContentProblemXML header value for problem details (RFC 7807) XML data.
+	ContentProblemXML = "application/problem+xml"
 	// Default character encoding.
 	defaultCharset = "UTF-8"
 )
@@ -49,8 +61,11 @@ type Delims struct {
 // Options is a struct for specifying configuration options for the render.Render object.
 type Options struct {
 	// Directory to load templates. Default is "templates".
+	// If the FileSystem (or the Asset) is set, it's the directory of the templates inside it.
 	Directory string
-	// Asset function to use in place of directory. Defaults to nil.
+	// FileSystem to load the templates from, in place of the OS, i.e utils.NewMapFS, utils.NewZipFS or http.Dir. Defaults to nil.
+	FileSystem http.FileSystem
+	// Asset function to use in place of directory, it's an alternative to the FileSystem for the go-bindata. Defaults to nil.
 	Asset func(name string) ([]byte, error)
 	// AssetNames function to use in place of directory. Defaults to nil.
 	AssetNames func() []string
@@ -64,8 +79,14 @@ type Options struct {
 	Delims Delims
 	// Appends the given character set to the Content-Type header. Default is "UTF-8".
 	Charset string
-	// Gzip enable it if you want to render with gzip compression. Default is false
+	// Gzip enable it if you want to render with compression, the encoding (br, gzip or deflate)
+	// is negotiated per request with the client's Accept-Encoding header. Default is false
 	Gzip bool
+	// CompressEncodings the encodings which are offered to the clients when Gzip is enabled, in order of preference.
+	// Default is ["br", "gzip", "deflate"]
+	CompressEncodings []string
+	// CompressMinLength bodies smaller than this length (in bytes) are not compressed. Default is 1024
+	CompressMinLength int
 	// Outputs human readable JSON.
 	IndentJSON bool
 	// Outputs human readable XML. Default is false.
@@ -76,18 +97,32 @@ type Options struct {
 	PrefixXML []byte
 	// Allows changing of output to XHTML instead of HTML. Default is "text/html".
 	HTMLContentType string
-	// If IsDevelopment is set to true, this will recompile the templates on every request. Default is false.
+	// If IsDevelopment is set to true, this will recompile the templates when a file under the Directory changes
+	// (or on every request if the FileSystem or the Asset is used) and the compile errors are shown as an error page. Default is false.
 	IsDevelopment bool
 	// Unescape HTML characters "&<>" to their original values. Default is false.
 	UnEscapeHTML bool
 	// Streams JSON responses instead of marshalling prior to sending. Default is false.
 	StreamingJSON bool
+	// StreamingHTML set to true to execute the templates straight into the response's body stream, instead of a buffer,
+	// it lowers the memory and the time to the first byte for large pages. The first StreamingHTMLHeadSize bytes are still buffered,
+	// so the errors which happen before that are rendered as usual, a later error truncates the response.
+	// The rest of the execution happens after the handler is returned, so the binding and the per-request funcs should not keep the Context.
+	// It's ignored on IsDevelopment. Default is false
+	StreamingHTML bool
+	// StreamingHTMLHeadSize the size of the buffered head of the StreamingHTML. Default is 4096
+	StreamingHTMLHeadSize int
+	// MarkdownSanitize set to true to clean the html which is converted from markdown from any unsafe html, i.e scripts.
+	// Enable it if the markdown contents are coming from your users. Default is false
+	MarkdownSanitize bool
 	// Require that all partials executed in the layout are implemented in all templates using the layout. Default is false.
 	RequirePartials bool
 	// Deprecated: Use the above `RequirePartials` instead of this. As of Go 1.6, blocks are built in. Default is false.
 	RequireBlocks bool
 	// Disables automatic rendering of http.StatusInternalServerError when an error occurs. Default is false.
 	DisableHTTPErrorRendering bool
+	// Engine the template engine which loads and executes the templates. Default is the html/template engine (NewHTMLEngine()).
+	Engine TemplateEngine
 }
 
 // HTMLOptions is a struct for overriding some rendering Options for specific HTML call.
@@ -101,8 +136,14 @@ type HTMLOptions struct {
 type Render struct {
 	// Customize Secure with an Options struct.
 	opt             Options
-	templates       *template.Template
+	engine          TemplateEngine
 	compiledCharset string
+	// mu protects the templates while they are re-compiled, used only if IsDevelopment is true
+	mu sync.RWMutex
+	// compileErr is the last compile error, used only if IsDevelopment is true
+	compileErr error
+	// stopWatch stops the watcher of the templates directory, nil if the templates are not watched
+	stopWatch func()
 }
 
 // New constructs a new Render instance with the supplied options.
@@ -114,22 +155,46 @@ func New(options ...Options) *Render {
 		o = options[0]
 	}
 
-	r := Render{
+	r := &Render{
 		opt: o,
 	}
 
 	r.prepareOptions()
 	if err := r.compileTemplates(); err != nil {
-		// We don't care about IsDevelopment, it's before server's run, panic
-		panic(err)
+		if !r.opt.IsDevelopment {
+			panic(err)
+		}
+		// on development the error is shown by the HTML until the template is fixed
+		r.compileErr = err
+	}
+
+	if r.opt.IsDevelopment {
+		r.watchTemplates()
 	}
 
-	// Create a new buffer pool for writing templates into.
-	if bufPool == nil {
-		bufPool = NewBufferPool(64)
+	return r
+}
+
+// watchTemplates re-compiles the templates when a file under the Directory changes,
+// the templates of the FileSystem or the Asset, or of a directory which can't be watched, are re-compiled on every HTML request instead
+func (r *Render) watchTemplates() {
+	if r.opt.FileSystem != nil || r.opt.Asset != nil && r.opt.AssetNames != nil || !utils.DirectoryExists(r.opt.Directory) {
+		return
 	}
 
-	return &r
+	r.stopWatch = utils.WatchDirectoryChanges(r.opt.Directory, func(string) {
+		r.mu.Lock()
+		r.compileErr = r.compileTemplates()
+		r.mu.Unlock()
+	})
+}
+
+// Close stops the watcher of the templates, if any
+func (r *Render) Close() {
+	if r.stopWatch != nil {
+		r.stopWatch()
+		r.stopWatch = nil
+	}
 }
 
 func (r *Render) prepareOptions() {
@@ -148,172 +213,41 @@ func (r *Render) prepareOptions() {
 	if len(r.opt.HTMLContentType) == 0 {
 		r.opt.HTMLContentType = ContentHTML
 	}
-}
-
-func (r *Render) compileTemplates() error {
-	if r.opt.Asset == nil || r.opt.AssetNames == nil {
-		return r.compileTemplatesFromDir()
-
+	if r.opt.StreamingHTMLHeadSize <= 0 {
+		r.opt.StreamingHTMLHeadSize = DefaultStreamingHTMLHeadSize
 	}
-	return r.compileTemplatesFromAsset()
-}
-
-func (r *Render) compileTemplatesFromDir() error {
-	var templateErr error
-	dir := r.opt.Directory
-	r.templates = template.New(dir)
-	r.templates.Delims(r.opt.Delims.Left, r.opt.Delims.Right)
-
-	// Walk the supplied directory and compile any files that match our extension list.
-	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		// Fix same-extension-dirs bug: some dir might be named to: "users.tmpl", "local.html".
-		// These dirs should be excluded as they are not valid golang templates, but files under
-		// them should be treat as normal.
-		// If is a dir, return immediately (dir is not a valid golang template).
-		if info == nil || info.IsDir() {
-			return nil
-		}
-
-		rel, err := filepath.Rel(dir, path)
-		if err != nil {
-			return err
-		}
-
-		ext := ""
-		if strings.Index(rel, ".") != -1 {
-			ext = filepath.Ext(rel)
-		}
+	if len(r.opt.CompressEncodings) == 0 {
+		r.opt.CompressEncodings = DefaultCompressEncodings
+	}
+	if r.opt.CompressMinLength <= 0 {
+		r.opt.CompressMinLength = DefaultCompressMinLength
+	}
+	// the markdown funcs and the defaults of the per-request funcs go first, so the user's funcs can override them
+	r.opt.Funcs = append([]template.FuncMap{r.markdownFuncs(), declaredFuncs()}, r.opt.Funcs...)
 
-		for _, extension := range r.opt.Extensions {
-			if ext == extension {
-				buf, err := ioutil.ReadFile(path)
-				if err != nil {
-					panic(err)
-				}
-
-				name := (rel[0 : len(rel)-len(ext)])
-				tmpl := r.templates.New(filepath.ToSlash(name))
-
-				// Add our funcmaps.
-				for _, funcs := range r.opt.Funcs {
-					tmpl.Funcs(funcs)
-				}
-
-				if !r.opt.IsDevelopment {
-					//panic in production.
-					template.Must(tmpl.Funcs(helperFuncs).Parse(string(buf)))
-				} else {
-					if _, templateErr = tmpl.Funcs(helperFuncs).Parse(string(buf)); templateErr != nil {
-						break //we dont continue to the next templates
-					}
-
-				}
-
-				break
-			}
-		}
-		return nil
-	})
-	return templateErr
+	if r.opt.Engine == nil {
+		r.opt.Engine = NewHTMLEngine()
+	}
+	r.engine = r.opt.Engine
 }
 
-func (r *Render) compileTemplatesFromAsset() error {
-	var templateErr error
-	dir := r.opt.Directory
-	r.templates = template.New(dir)
-	r.templates.Delims(r.opt.Delims.Left, r.opt.Delims.Right)
-
-	for _, path := range r.opt.AssetNames() {
-		if !strings.HasPrefix(path, dir) {
-			continue
-		}
-
-		rel, err := filepath.Rel(dir, path)
-		if err != nil {
-			panic(err)
-		}
-
-		ext := ""
-		if strings.Index(rel, ".") != -1 {
-			ext = "." + strings.Join(strings.Split(rel, ".")[1:], ".")
-		}
-
-		for _, extension := range r.opt.Extensions {
-			if ext == extension {
-
-				buf, err := r.opt.Asset(path)
-				if err != nil {
-					panic(err)
-				}
-
-				name := (rel[0 : len(rel)-len(ext)])
-				tmpl := r.templates.New(filepath.ToSlash(name))
-
-				// Add our funcmaps.
-				for _, funcs := range r.opt.Funcs {
-					tmpl.Funcs(funcs)
-				}
-
-				if !r.opt.IsDevelopment {
-					//panic in production.
-					template.Must(tmpl.Funcs(helperFuncs).Parse(string(buf)))
-				} else {
-					if _, templateErr = tmpl.Funcs(helperFuncs).Parse(string(buf)); templateErr != nil {
-						break //we dont continue to the next templates
-					}
-				}
-				break
-			}
-		}
-	}
-	return templateErr
+func (r *Render) compileTemplates() error {
+	return r.engine.Load(r.opt)
 }
 
 // TemplateLookup is a wrapper around template.Lookup and returns
 // the template with the given name that is associated with t, or nil
 // if there is no such template.
 func (r *Render) TemplateLookup(t string) *template.Template {
-	return r.templates.Lookup(t)
-}
-
-func (r *Render) execute(name string, binding interface{}) (*bytes.Buffer, error) {
-	buf := new(bytes.Buffer)
-	return buf, r.templates.ExecuteTemplate(buf, name, binding)
+	if e, ok := r.engine.(*HTMLEngine); ok && e.Templates != nil {
+		return e.Templates.Lookup(t)
+	}
+	return nil
 }
 
-func (r *Render) addLayoutFuncs(name string, binding interface{}) {
-	funcs := template.FuncMap{
-		"yield": func() (template.HTML, error) {
-			buf, err := r.execute(name, binding)
-			// Return safe HTML here since we are rendering our own template.
-			return template.HTML(buf.String()), err
-		},
-		"current": func() (string, error) {
-			return name, nil
-		},
-		"block": func(partialName string) (template.HTML, error) {
-			log.Print("Render's `block` implementation is now depericated. Use `partial` as a drop in replacement.")
-			fullPartialName := fmt.Sprintf("%s-%s", partialName, name)
-			if r.opt.RequireBlocks || r.TemplateLookup(fullPartialName) != nil {
-				buf, err := r.execute(fullPartialName, binding)
-				// Return safe HTML here since we are rendering our own template.
-				return template.HTML(buf.String()), err
-			}
-			return "", nil
-		},
-		"partial": func(partialName string) (template.HTML, error) {
-			fullPartialName := fmt.Sprintf("%s-%s", partialName, name)
-			if r.opt.RequirePartials || r.TemplateLookup(fullPartialName) != nil {
-				buf, err := r.execute(fullPartialName, binding)
-				// Return safe HTML here since we are rendering our own template.
-				return template.HTML(buf.String()), err
-			}
-			return "", nil
-		},
-	}
-	if tpl := r.templates.Lookup(name); tpl != nil {
-		tpl.Funcs(funcs)
-	}
+// Engine returns the template engine which is used by this Render
+func (r *Render) Engine() TemplateEngine {
+	return r.engine
 }
 
 func (r *Render) prepareHTMLOptions(htmlOpt []HTMLOptions) HTMLOptions {
@@ -328,11 +262,9 @@ func (r *Render) prepareHTMLOptions(htmlOpt []HTMLOptions) HTMLOptions {
 
 // Render is the generic function called by XML, JSON, Data, HTML, and can be called by custom implementations.
 func (r *Render) Render(ctx *fasthttp.RequestCtx, e Engine, data interface{}) error {
-	var err error
-	if r.opt.Gzip {
-		err = e.RenderGzip(ctx, data)
-	} else {
-		err = e.Render(ctx, data)
+	err := e.Render(ctx, data)
+	if err == nil && r.opt.Gzip {
+		err = r.compress(ctx)
 	}
 
 	if err != nil && !r.opt.DisableHTTPErrorRendering {
@@ -358,19 +290,22 @@ func (r *Render) Data(ctx *fasthttp.RequestCtx, status int, v []byte) error {
 
 // HTML builds up the response from the specified template and bindings.
 func (r *Render) HTML(ctx *fasthttp.RequestCtx, status int, name string, binding interface{}, htmlOpt ...HTMLOptions) error {
-	// If we are in development mode, recompile the templates on every HTML request.
 	if r.opt.IsDevelopment {
-		if err := r.compileTemplates(); err != nil {
-			return err
+		if r.stopWatch == nil {
+			// the templates are not watched, recompile them on every HTML request.
+			r.mu.Lock()
+			r.compileErr = r.compileTemplates()
+			r.mu.Unlock()
+		}
+
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		if r.compileErr != nil {
+			return r.developmentError(ctx, r.compileErr)
 		}
 	}
 
 	opt := r.prepareHTMLOptions(htmlOpt)
-	// Assign a layout if there is one.
-	if len(opt.Layout) > 0 {
-		r.addLayoutFuncs(name, binding)
-		name = opt.Layout
-	}
 
 	head := Head{
 		ContentType: r.opt.HTMLContentType + r.compiledCharset,
@@ -378,9 +313,17 @@ func (r *Render) HTML(ctx *fasthttp.RequestCtx, status int, name string, binding
 	}
 
 	h := HTML{
-		Head:      head,
-		Name:      name,
-		Templates: r.templates,
+		Head:   head,
+		Name:   name,
+		Layout: opt.Layout,
+		Engine: r.engine,
+		Funcs:  RequestFuncs(ctx),
+	}
+
+	// on development the templates may be recompiled while they are executed, so they are never streamed
+	if r.opt.StreamingHTML && !r.opt.IsDevelopment {
+		h.StreamHeadSize = r.opt.StreamingHTMLHeadSize
+		h.StreamEncoding = r.streamEncoding(ctx)
 	}
 
 	return r.Render(ctx, h, binding)
@@ -420,6 +363,40 @@ func (r *Render) JSONP(ctx *fasthttp.RequestCtx, status int, callback string, v
 	return r.Render(ctx, j, v)
 }
 
+// JSONStream streams the values of the channel as newline-delimited JSON or as a JSON array, look JSONStreamOptions.
+func (r *Render) JSONStream(ctx *fasthttp.RequestCtx, status int, ch <-chan interface{}, options ...JSONStreamOptions) error {
+	return r.jsonStream(ctx, status, ch, options)
+}
+
+// JSONStreamFunc same as JSONStream but the values are taken from the next func, until it returns false.
+func (r *Render) JSONStreamFunc(ctx *fasthttp.RequestCtx, status int, next func() (interface{}, bool), options ...JSONStreamOptions) error {
+	return r.jsonStream(ctx, status, next, options)
+}
+
+func (r *Render) jsonStream(ctx *fasthttp.RequestCtx, status int, source interface{}, options []JSONStreamOptions) error {
+	var opt JSONStreamOptions
+	if len(options) > 0 {
+		opt = options[0]
+	}
+
+	contentType := ContentNDJSON
+	if opt.Array {
+		contentType = ContentJSON
+	}
+	head := Head{
+		ContentType: contentType + r.compiledCharset,
+		Status:      status,
+	}
+
+	j := JSONStream{
+		Head:              head,
+		JSONStreamOptions: opt,
+		StreamEncoding:    r.streamEncoding(ctx),
+	}
+
+	return r.Render(ctx, j, source)
+}
+
 // Text writes out a string as plain text.
 func (r *Render) Text(ctx *fasthttp.RequestCtx, status int, v string) error {
 	head := Head{
@@ -434,6 +411,104 @@ func (r *Render) Text(ctx *fasthttp.RequestCtx, status int, v string) error {
 	return r.Render(ctx, t, v)
 }
 
+// CSV writes the rows of a slice, array or channel of structs as csv response, the rows are streamed.
+func (r *Render) CSV(ctx *fasthttp.RequestCtx, status int, v interface{}) error {
+	head := Head{
+		ContentType: ContentCSV + r.compiledCharset,
+		Status:      status,
+	}
+
+	c := CSV{
+		Head:           head,
+		StreamEncoding: r.streamEncoding(ctx),
+	}
+
+	return r.Render(ctx, c, v)
+}
+
+// YAML marshals the given interface object and writes the YAML response.
+func (r *Render) YAML(ctx *fasthttp.RequestCtx, status int, v interface{}) error {
+	head := Head{
+		ContentType: ContentYAML + r.compiledCharset,
+		Status:      status,
+	}
+
+	y := YAML{
+		Head: head,
+	}
+
+	return r.Render(ctx, y, v)
+}
+
+// MsgPack marshals the given interface object and writes the MessagePack response.
+func (r *Render) MsgPack(ctx *fasthttp.RequestCtx, status int, v interface{}) error {
+	head := Head{
+		ContentType: ContentMsgPack,
+		Status:      status,
+	}
+
+	m := MsgPack{
+		Head: head,
+	}
+
+	return r.Render(ctx, m, v)
+}
+
+// Protobuf marshals the given proto.Message and writes the Protobuf response.
+func (r *Render) Protobuf(ctx *fasthttp.RequestCtx, status int, v proto.Message) error {
+	head := Head{
+		ContentType: ContentProtobuf,
+		Status:      status,
+	}
+
+	p := Protobuf{
+		Head: head,
+	}
+
+	return r.Render(ctx, p, v)
+}
+
+// Problem marshals the problem details object (RFC 7807) and writes it as application/problem+json response
+// or as application/problem+xml if the client prefers xml, based on its Accept header.
+// The JSON and XML prefixes are not written, the clients of the problems expect the plain documents.
+func (r *Render) Problem(ctx *fasthttp.RequestCtx, status int, v interface{}) error {
+	contentType := NegotiateContentType(string(ctx.Request.Header.Peek(Accept)),
+		[]string{ContentProblemJSON, ContentJSON, ContentProblemXML, ContentXML, "application/xml"})
+	AddVary(&ctx.Response.Header, Accept)
+
+	head := Head{
+		ContentType: ContentProblemJSON + r.compiledCharset,
+		Status:      status,
+	}
+
+	if strings.HasSuffix(contentType, "xml") {
+		head.ContentType = ContentProblemXML + r.compiledCharset
+		return r.Render(ctx, XML{Head: head, Indent: r.opt.IndentXML}, v)
+	}
+
+	j := JSON{
+		Head:         head,
+		Indent:       r.opt.IndentJSON,
+		UnEscapeHTML: r.opt.UnEscapeHTML,
+	}
+	return r.Render(ctx, j, v)
+}
+
+// Markdown converts the markdown source to html and writes it as html response.
+func (r *Render) Markdown(ctx *fasthttp.RequestCtx, status int, markdown []byte) error {
+	head := Head{
+		ContentType: ContentMarkdown + r.compiledCharset,
+		Status:      status,
+	}
+
+	m := Markdown{
+		Head:     head,
+		Sanitize: r.opt.MarkdownSanitize,
+	}
+
+	return r.Render(ctx, m, markdown)
+}
+
 // XML marshals the given interface object and writes the XML response.
 func (r *Render) XML(ctx *fasthttp.RequestCtx, status int, v interface{}) error {
 	head := Head{
@@ -449,3 +524,21 @@ func (r *Render) XML(ctx *fasthttp.RequestCtx, status int, v interface{}) error
 
 	return r.Render(ctx, x, v)
 }
+
+// developmentError writes a development error page with the error to the client and returns the error
+func (r *Render) developmentError(ctx *fasthttp.RequestCtx, err error) error {
+	if !r.opt.DisableHTTPErrorRendering {
+		ctx.Response.Header.Set(ContentType, ContentHTML+r.compiledCharset)
+		ctx.Response.SetStatusCode(fasthttp.StatusInternalServerError)
+		ctx.Response.SetBodyString(`<!DOCTYPE html>
+<html>
+<head><title>Template Error</title></head>
+<body>
+<h1>Template Error</h1>
+<p>The templates of '` + template.HTMLEscapeString(r.opt.Directory) + `' could not be compiled, the page will work again after the template is fixed and saved.</p>
+<pre>` + template.HTMLEscapeString(err.Error()) + `</pre>
+</body>
+</html>`)
+	}
+	return err
+}
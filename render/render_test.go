@@ -0,0 +1,88 @@
+package render
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestRenderWatchTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "index.html")
+	if err = ioutil.WriteFile(filename, []byte("v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(Options{Directory: dir, IsDevelopment: true})
+	defer r.Close()
+	if r.stopWatch == nil {
+		t.Skip("the watcher can't be started on this system, the templates are re-compiled on every request")
+	}
+
+	if body := renderHTML(t, r, "index", nil).Response.Body(); string(body) != "v1" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	if err = ioutil.WriteFile(filename, []byte("v2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		body := string(renderHTML(t, r, "index", nil).Response.Body())
+		if body == "v2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the template to be re-compiled after the change, body %q", body)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestRenderReloadOnRequest(t *testing.T) {
+	files := map[string][]byte{"index.html": []byte("v1")}
+	// the assets are not watched, the templates are re-compiled on every request
+	r := New(Options{
+		Directory: "templates",
+		Asset: func(name string) ([]byte, error) {
+			if contents, ok := files[strings.TrimPrefix(name, "templates/")]; ok {
+				return contents, nil
+			}
+			return nil, os.ErrNotExist
+		},
+		AssetNames:    func() []string { return []string{"templates/index.html"} },
+		IsDevelopment: true,
+	})
+	if r.stopWatch != nil {
+		t.Fatal("expected the assets not to be watched")
+	}
+
+	if body := renderHTML(t, r, "index", nil).Response.Body(); string(body) != "v1" {
+		t.Fatalf("unexpected body %q", body)
+	}
+	files = map[string][]byte{"index.html": []byte("v2")}
+	if body := renderHTML(t, r, "index", nil).Response.Body(); string(body) != "v2" {
+		t.Fatalf("expected the template to be re-compiled on the request, body %q", body)
+	}
+
+	// a broken template is rendered as an error until it's fixed
+	files = map[string][]byte{"index.html": []byte("{{ if }")}
+	ctx := new(fasthttp.RequestCtx)
+	if err := r.HTML(ctx, fasthttp.StatusOK, "index", nil); err == nil {
+		t.Fatal("expected the compile error")
+	}
+	files = map[string][]byte{"index.html": []byte("v3")}
+	if body := renderHTML(t, r, "index", nil).Response.Body(); string(body) != "v3" {
+		t.Fatalf("unexpected body after the fix %q", body)
+	}
+}
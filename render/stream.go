@@ -0,0 +1,70 @@
+package render
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/valyala/fasthttp"
+)
+
+// streamWrapperKey is the fasthttp's user value key of the body stream wrapper of the request.
+const streamWrapperKey = "iris.render.stream"
+
+// SetStreamWrapper sets the wrapper of the body stream writers of this request, i.e a compressor,
+// the streaming renderers (JSONStream, CSV and the streaming HTML) pass their writer through it, look SetBodyStreamWriter.
+// The iris.Context sets it on WrapStream.
+func SetStreamWrapper(ctx *fasthttp.RequestCtx, wrapper func(func(*bufio.Writer)) func(*bufio.Writer)) {
+	ctx.SetUserValue(streamWrapperKey, wrapper)
+}
+
+// SetBodyStreamWriter sets the body stream writer of the response, through the stream wrapper of the request, if any.
+func SetBodyStreamWriter(ctx *fasthttp.RequestCtx, sw func(*bufio.Writer)) {
+	if wrapper, ok := ctx.UserValue(streamWrapperKey).(func(func(*bufio.Writer)) func(*bufio.Writer)); ok && wrapper != nil {
+		sw = wrapper(sw)
+	}
+	ctx.SetBodyStreamWriter(sw)
+}
+
+// CompressStreamWriter returns the body stream writer which compresses the output of the sw with the encoding ("gzip", "deflate" or "br"),
+// each flush of the sw's writer is flushed to the client too, so the streaming keeps working.
+// The Content-Encoding header is not set, it's up to the caller.
+func CompressStreamWriter(encoding string, sw func(*bufio.Writer)) func(*bufio.Writer) {
+	return func(w *bufio.Writer) {
+		cw, err := AcquireCompressWriter(w, encoding)
+		if err != nil {
+			return
+		}
+		fw := bufio.NewWriter(&flushWriter{cw: cw, w: w})
+		sw(fw)
+		fw.Flush()
+		cw.Close()
+		ReleaseCompressWriter(encoding, cw)
+	}
+}
+
+// flushWriter writes to the compressor and flushes the compressed data to the client's writer.
+type flushWriter struct {
+	cw CompressWriter
+	w  *bufio.Writer
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.cw.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if err = f.cw.Flush(); err != nil {
+		return n, err
+	}
+	return n, f.w.Flush()
+}
+
+// SetBodyStream same as SetBodyStreamWriter but the body is read from the r, it's closed when it's finished, if it's an io.Closer.
+func SetBodyStream(ctx *fasthttp.RequestCtx, r io.Reader) {
+	SetBodyStreamWriter(ctx, func(w *bufio.Writer) {
+		io.Copy(w, r)
+		if c, ok := r.(io.Closer); ok {
+			c.Close()
+		}
+	})
+}
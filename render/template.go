@@ -0,0 +1,74 @@
+package render
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/kataras/iris/utils"
+)
+
+// TemplateEngine is the interface which a template engine should implement in order to be used by the Render.
+// The names of the templates are their paths relative to the Options.Directory, without the extension, i.e "users/index".
+type TemplateEngine interface {
+	// Load loads and compiles the templates from the Options.Directory, inside the Options.FileSystem if any,
+	// it is called once on New and on every HTML call if Options.IsDevelopment is true.
+	Load(Options) error
+	// Lookup returns true if a template with the given name exists.
+	Lookup(name string) bool
+	// Execute executes the template with the given binding and writes the result to the writer.
+	// If layout is not blank ("") then the template is executed inside the layout template,
+	// the layout shows the template's contents through its "yield".
+	Execute(w io.Writer, name string, binding interface{}, layout string) error
+}
+
+// templatesFS returns the file system of the templates and the directory of the templates inside it,
+// the Options.FileSystem, the Options.Asset or the OS directory of the Options.Directory.
+func templatesFS(opt Options) (http.FileSystem, string) {
+	if opt.FileSystem != nil {
+		return opt.FileSystem, opt.Directory
+	}
+	if opt.Asset != nil && opt.AssetNames != nil {
+		return utils.NewAssetFS(opt.Asset, opt.AssetNames), opt.Directory
+	}
+	return http.Dir(opt.Directory), "/"
+}
+
+// walkTemplates calls the visitor for each one of the template files of the Options.Directory
+// (inside the Options.FileSystem or the Options.Asset, if any) which have one of the Options.Extensions.
+// The name passed to the visitor is the path of the file relative to the Options.Directory, without the extension,
+// the filename is the same path with the extension.
+func walkTemplates(opt Options, visitor func(name string, filename string, contents []byte) error) error {
+	fs, dir := templatesFS(opt)
+	dir = path.Clean("/" + dir)
+
+	return utils.WalkFS(fs, dir, func(filename string, info os.FileInfo, err error) error {
+		// Fix same-extension-dirs bug: some dir might be named to: "users.tmpl", "local.html".
+		// These dirs should be excluded as they are not valid golang templates, but files under
+		// them should be treat as normal.
+		// If is a dir, return immediately (dir is not a valid golang template).
+		if info == nil || info.IsDir() {
+			return nil
+		}
+
+		rel := strings.TrimPrefix(strings.TrimPrefix(filename, dir), "/")
+		for _, extension := range opt.Extensions {
+			// the extension may have more than one dots, i.e ".tmpl.html"
+			if len(rel) > len(extension) && strings.HasSuffix(rel, extension) {
+				buf, err := utils.ReadFile(fs, filename)
+				if err != nil {
+					return err
+				}
+				return visitor(rel[0:len(rel)-len(extension)], rel, buf)
+			}
+		}
+		return nil
+	})
+}
+
+// readTemplateFile reads a file, by its path relative to the Options.Directory, from the file system of the templates.
+func readTemplateFile(fs http.FileSystem, dir string, rel string) ([]byte, error) {
+	return utils.ReadFile(fs, path.Join("/", dir, rel))
+}
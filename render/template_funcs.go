@@ -0,0 +1,63 @@
+package render
+
+import (
+	"html/template"
+	"io"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+// requestFuncsKey is the fasthttp's user value key of the per-request template funcs.
+const requestFuncsKey = "iris.render.funcs"
+
+// TemplateFuncsEngine is implemented by the template engines which can execute a template with extra funcs,
+// which are added to (or override) the Options.Funcs for this execution only, without recompiling the templates.
+// All the built-in engines implement it.
+type TemplateFuncsEngine interface {
+	ExecuteWithFuncs(w io.Writer, name string, binding interface{}, layout string, funcs map[string]interface{}) error
+}
+
+var (
+	declaredRequestFuncs   = make(template.FuncMap)
+	declaredRequestFuncsMu sync.RWMutex
+)
+
+// DeclareRequestFunc declares a per-request template func by its name and its default,
+// the default is used by the requests which don't set that func.
+// The html/template and the handlebars need to know the funcs when the templates are compiled,
+// so the per-request funcs should be declared before the Render's New, i.e by the constructor of a middleware,
+// or have a default in the Options.Funcs.
+func DeclareRequestFunc(name string, defaultFunc interface{}) {
+	declaredRequestFuncsMu.Lock()
+	declaredRequestFuncs[name] = defaultFunc
+	declaredRequestFuncsMu.Unlock()
+}
+
+// declaredFuncs returns a copy of the declared per-request funcs.
+func declaredFuncs() template.FuncMap {
+	declaredRequestFuncsMu.RLock()
+	funcs := make(template.FuncMap, len(declaredRequestFuncs))
+	for k, v := range declaredRequestFuncs {
+		funcs[k] = v
+	}
+	declaredRequestFuncsMu.RUnlock()
+	return funcs
+}
+
+// SetRequestFunc sets a template func for this request only, it is merged with the Options.Funcs when a template is rendered.
+// Its type should be the same as the default's, look DeclareRequestFunc.
+func SetRequestFunc(ctx *fasthttp.RequestCtx, name string, fn interface{}) {
+	funcs, _ := ctx.UserValue(requestFuncsKey).(map[string]interface{})
+	if funcs == nil {
+		funcs = make(map[string]interface{})
+		ctx.SetUserValue(requestFuncsKey, funcs)
+	}
+	funcs[name] = fn
+}
+
+// RequestFuncs returns the template funcs of this request, which are set by SetRequestFunc, it may be nil.
+func RequestFuncs(ctx *fasthttp.RequestCtx) map[string]interface{} {
+	funcs, _ := ctx.UserValue(requestFuncsKey).(map[string]interface{})
+	return funcs
+}
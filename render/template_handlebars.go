@@ -0,0 +1,174 @@
+package render
+
+import (
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/aymerick/raymond"
+)
+
+// HandlebarsEngine is the TemplateEngine of the handlebars templates, https://github.com/aymerick/raymond.
+//
+// Each template can use the other templates as partials, by their names, i.e {{> partials/header }}.
+// The Options.Funcs which return one value are registered as helpers,
+// the per-request funcs of ExecuteWithFuncs override the helpers of the same name and type.
+// The layout shows the template's contents by {{{ yield }}}.
+type HandlebarsEngine struct {
+	opt       Options
+	templates map[string]*raymond.Template
+	mu        sync.RWMutex
+}
+
+var (
+	_ TemplateEngine      = &HandlebarsEngine{}
+	_ TemplateFuncsEngine = &HandlebarsEngine{}
+
+	raymondOptionsType = reflect.TypeOf((*raymond.Options)(nil))
+)
+
+// requestFuncsData is the data frame's key of the per-request funcs.
+const requestFuncsData = "iris.funcs"
+
+// NewHandlebarsEngine returns a new handlebars engine.
+func NewHandlebarsEngine() *HandlebarsEngine {
+	return &HandlebarsEngine{}
+}
+
+// Load parses all the templates and registers them as partials and the Options.Funcs as helpers to each one of them.
+func (e *HandlebarsEngine) Load(opt Options) error {
+	templates := make(map[string]*raymond.Template)
+
+	err := walkTemplates(opt, func(name string, filename string, contents []byte) error {
+		tmpl, err := raymond.Parse(string(contents))
+		if err != nil {
+			return err
+		}
+		templates[name] = tmpl
+		return nil
+	})
+
+	if err != nil {
+		if !opt.IsDevelopment {
+			panic(err)
+		}
+		return err
+	}
+
+	helpers := make(map[string]interface{})
+	for _, funcs := range opt.Funcs {
+		for k, v := range funcs {
+			// handlebars helpers should return only one value
+			if fn := reflect.ValueOf(v); fn.Kind() == reflect.Func && fn.Type().NumOut() == 1 {
+				helpers[k] = requestHelper(k, fn)
+			}
+		}
+	}
+	helpers["yield"] = func(options *raymond.Options) raymond.SafeString {
+		return raymond.SafeString(options.DataStr("yield"))
+	}
+	helpers["current"] = func(options *raymond.Options) string {
+		return options.DataStr("current")
+	}
+
+	for _, tmpl := range templates {
+		tmpl.RegisterHelpers(helpers)
+		for partialName, partial := range templates {
+			tmpl.RegisterPartialTemplate(partialName, partial)
+		}
+	}
+
+	e.mu.Lock()
+	e.opt = opt
+	e.templates = templates
+	e.mu.Unlock()
+	return nil
+}
+
+// Lookup returns true if a template with the given name exists.
+func (e *HandlebarsEngine) Lookup(name string) bool {
+	e.mu.RLock()
+	_, ok := e.templates[name]
+	e.mu.RUnlock()
+	return ok
+}
+
+// requestHelper returns a helper which calls the per-request func of that name, if it has the same type, instead of the fn.
+// Handlebars can't pass a variable number of params to a helper, so a variadic fn, i.e the i18n's translate,
+// is called with its fixed params only, i.e {{translate "hello"}}.
+func requestHelper(name string, fn reflect.Value) interface{} {
+	typ := fn.Type()
+	numIn := typ.NumIn()
+	if typ.IsVariadic() {
+		numIn--
+	}
+
+	// the options are needed to find the request's funcs, add them if the fn doesn't accept them
+	hasOptions := !typ.IsVariadic() && numIn > 0 && typ.In(numIn-1) == raymondOptionsType
+	helperType := typ
+	if !hasOptions {
+		in := make([]reflect.Type, numIn+1)
+		for i := 0; i < numIn; i++ {
+			in[i] = typ.In(i)
+		}
+		in[len(in)-1] = raymondOptionsType
+		helperType = reflect.FuncOf(in, []reflect.Type{typ.Out(0)}, false)
+	}
+
+	return reflect.MakeFunc(helperType, func(args []reflect.Value) []reflect.Value {
+		call := fn
+		options := args[len(args)-1].Interface().(*raymond.Options)
+		if funcs, ok := options.Data(requestFuncsData).(map[string]interface{}); ok {
+			if requestFn := reflect.ValueOf(funcs[name]); requestFn.IsValid() && requestFn.Type() == typ {
+				call = requestFn
+			}
+		}
+		if !hasOptions {
+			args = args[:len(args)-1]
+		}
+		if typ.IsVariadic() {
+			return call.CallSlice(append(args, reflect.Zero(typ.In(numIn))))
+		}
+		return call.Call(args)
+	}).Interface()
+}
+
+// Execute executes the template with the given binding and writes the result to the writer.
+func (e *HandlebarsEngine) Execute(w io.Writer, name string, binding interface{}, layout string) error {
+	return e.ExecuteWithFuncs(w, name, binding, layout, nil)
+}
+
+// ExecuteWithFuncs same as Execute but the funcs override the helpers of the same name and type for this execution only.
+// The helpers are registered on Load, so a per-request func should have a default, look DeclareRequestFunc.
+func (e *HandlebarsEngine) ExecuteWithFuncs(w io.Writer, name string, binding interface{}, layout string, funcs map[string]interface{}) error {
+	e.mu.RLock()
+	tmpl, layoutTmpl := e.templates[name], e.templates[layout]
+	e.mu.RUnlock()
+
+	if tmpl == nil {
+		return ErrTemplateNotFound.Format(name)
+	}
+
+	data := raymond.NewDataFrame()
+	data.Set("current", name)
+	if len(funcs) > 0 {
+		data.Set(requestFuncsData, funcs)
+	}
+	contents, err := tmpl.ExecWith(binding, data)
+	if err != nil {
+		return err
+	}
+
+	if layout != "" {
+		if layoutTmpl == nil {
+			return ErrTemplateNotFound.Format(layout)
+		}
+		data.Set("yield", contents)
+		if contents, err = layoutTmpl.ExecWith(binding, data); err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, contents)
+	return err
+}
@@ -0,0 +1,207 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"reflect"
+)
+
+// requestTemplatesPoolSize the number of the clones of the templates which are kept for the executions with per-request funcs.
+const requestTemplatesPoolSize = 64
+
+// HTMLEngine is the TemplateEngine of the standard html/template package, it's the default engine.
+type HTMLEngine struct {
+	// Templates the compiled templates, it's nil before the Load.
+	Templates *template.Template
+	// master is a never executed clone of the Templates, the clones of the per-request funcs are cloned from it
+	// because the html/template can't clone a template after its execution, look ExecuteWithFuncs.
+	master *template.Template
+	// clones the reusable clones of the master, their funcs forward to the per-request funcs of their current execution
+	clones chan *requestTemplates
+	opt    Options
+}
+
+// requestTemplates is a clone of the templates whose Options.Funcs forward to the per-request funcs of its current execution,
+// the clones are reused, so the html/template escapes each one of them once, not on every request.
+type requestTemplates struct {
+	templates *template.Template
+	funcs     map[string]interface{}
+}
+
+var (
+	_ TemplateEngine      = &HTMLEngine{}
+	_ TemplateFuncsEngine = &HTMLEngine{}
+)
+
+// NewHTMLEngine returns a new html/template engine.
+func NewHTMLEngine() *HTMLEngine {
+	return &HTMLEngine{}
+}
+
+// Load compiles the templates, in production (Options.IsDevelopment is false) it panics on parse errors.
+func (e *HTMLEngine) Load(opt Options) error {
+	var templateErr error
+	e.opt = opt
+	templates := template.New(opt.Directory)
+	templates.Delims(opt.Delims.Left, opt.Delims.Right)
+
+	err := walkTemplates(opt, func(name string, filename string, contents []byte) error {
+		tmpl := templates.New(name)
+
+		// Add our funcmaps.
+		for _, funcs := range opt.Funcs {
+			tmpl.Funcs(funcs)
+		}
+
+		if !opt.IsDevelopment {
+			//panic in production.
+			template.Must(tmpl.Funcs(helperFuncs).Parse(string(contents)))
+		} else if _, templateErr = tmpl.Funcs(helperFuncs).Parse(string(contents)); templateErr != nil {
+			return templateErr //we dont continue to the next templates
+		}
+		return nil
+	})
+
+	if err != nil {
+		if !opt.IsDevelopment {
+			panic(err)
+		}
+		return err
+	}
+
+	master, err := templates.Clone()
+	if err != nil {
+		return err
+	}
+	e.Templates = templates
+	e.master = master
+	e.clones = make(chan *requestTemplates, requestTemplatesPoolSize)
+	return nil
+}
+
+// Lookup returns true if a template with the given name exists.
+func (e *HTMLEngine) Lookup(name string) bool {
+	return e.Templates != nil && e.Templates.Lookup(name) != nil
+}
+
+// Execute executes the template with the given binding and writes the result to the writer.
+// If layout is not blank ("") then the template is executed inside the layout, through the layout's {{ yield }}.
+func (e *HTMLEngine) Execute(w io.Writer, name string, binding interface{}, layout string) error {
+	return e.executeLayout(e.Templates, w, name, binding, layout)
+}
+
+// ExecuteWithFuncs same as Execute but the funcs override the Options.Funcs of the same name and type for this execution only.
+// The templates are not parsed or cloned per execution, a reused clone of them, whose funcs forward to the funcs of the execution, is executed instead,
+// so a per-request func should have a default, look DeclareRequestFunc.
+func (e *HTMLEngine) ExecuteWithFuncs(w io.Writer, name string, binding interface{}, layout string, funcs map[string]interface{}) error {
+	if len(funcs) == 0 {
+		return e.Execute(w, name, binding, layout)
+	}
+
+	master, clones := e.master, e.clones
+	var rt *requestTemplates
+	select {
+	case rt = <-clones:
+	default:
+		templates, err := master.Clone()
+		if err != nil {
+			return err
+		}
+		rt = e.newRequestTemplates(templates)
+	}
+
+	rt.funcs = funcs
+	err := e.executeLayout(rt.templates, w, name, binding, layout)
+	rt.funcs = nil
+
+	select {
+	case clones <- rt:
+	default: // Discard the clone if the pool is full.
+	}
+	return err
+}
+
+// newRequestTemplates replaces the Options.Funcs of the templates with funcs of the same type
+// which call the per-request func of the same name and type, if the current execution has one, or the Options.Funcs' one.
+func (e *HTMLEngine) newRequestTemplates(templates *template.Template) *requestTemplates {
+	rt := &requestTemplates{templates: templates}
+	forwards := make(template.FuncMap)
+	for _, funcs := range e.opt.Funcs {
+		for name, fn := range funcs {
+			forwards[name] = rt.forward(name, fn)
+		}
+	}
+	templates.Funcs(forwards)
+	return rt
+}
+
+// forward returns a func of the same type as the fn which calls the per-request func of the name, if it has the same type, or the fn.
+func (rt *requestTemplates) forward(name string, fn interface{}) interface{} {
+	defaultFn := reflect.ValueOf(fn)
+	if defaultFn.Kind() != reflect.Func {
+		return fn
+	}
+	typ := defaultFn.Type()
+	return reflect.MakeFunc(typ, func(args []reflect.Value) []reflect.Value {
+		call := defaultFn
+		if requestFn := reflect.ValueOf(rt.funcs[name]); requestFn.IsValid() && requestFn.Type() == typ {
+			call = requestFn
+		}
+		if typ.IsVariadic() {
+			return call.CallSlice(args)
+		}
+		return call.Call(args)
+	}).Interface()
+}
+
+func (e *HTMLEngine) executeLayout(templates *template.Template, w io.Writer, name string, binding interface{}, layout string) error {
+	// Assign a layout if there is one.
+	if len(layout) > 0 {
+		e.addLayoutFuncs(templates, name, binding)
+		name = layout
+	}
+	return templates.ExecuteTemplate(w, name, binding)
+}
+
+func (e *HTMLEngine) execute(templates *template.Template, name string, binding interface{}) (*bytes.Buffer, error) {
+	buf := new(bytes.Buffer)
+	return buf, templates.ExecuteTemplate(buf, name, binding)
+}
+
+func (e *HTMLEngine) addLayoutFuncs(templates *template.Template, name string, binding interface{}) {
+	funcs := template.FuncMap{
+		"yield": func() (template.HTML, error) {
+			buf, err := e.execute(templates, name, binding)
+			// Return safe HTML here since we are rendering our own template.
+			return template.HTML(buf.String()), err
+		},
+		"current": func() (string, error) {
+			return name, nil
+		},
+		"block": func(partialName string) (template.HTML, error) {
+			log.Print("Render's `block` implementation is now depericated. Use `partial` as a drop in replacement.")
+			fullPartialName := fmt.Sprintf("%s-%s", partialName, name)
+			if e.opt.RequireBlocks || templates.Lookup(fullPartialName) != nil {
+				buf, err := e.execute(templates, fullPartialName, binding)
+				// Return safe HTML here since we are rendering our own template.
+				return template.HTML(buf.String()), err
+			}
+			return "", nil
+		},
+		"partial": func(partialName string) (template.HTML, error) {
+			fullPartialName := fmt.Sprintf("%s-%s", partialName, name)
+			if e.opt.RequirePartials || templates.Lookup(fullPartialName) != nil {
+				buf, err := e.execute(templates, fullPartialName, binding)
+				// Return safe HTML here since we are rendering our own template.
+				return template.HTML(buf.String()), err
+			}
+			return "", nil
+		},
+	}
+	if tpl := templates.Lookup(name); tpl != nil {
+		tpl.Funcs(funcs)
+	}
+}
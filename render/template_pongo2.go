@@ -0,0 +1,160 @@
+package render
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"sync"
+
+	"github.com/flosch/pongo2"
+)
+
+// Pongo2Engine is the TemplateEngine of the pongo2 (django-syntax) templates, https://github.com/flosch/pongo2.
+//
+// The binding should be a map[string]interface{} or a pongo2.Context, any other binding is passed as "data".
+// The Options.Funcs are passed to the templates as functions too.
+// Layouts can be used with the {% extends %} tag or with the Options.Layout, the layout shows the template's contents by {{ yield }}.
+type Pongo2Engine struct {
+	// Set the pongo2's template set, it's nil before the Load.
+	Set   *pongo2.TemplateSet
+	opt   Options
+	names map[string]string // the names (without the extension) to the file names
+	mu    sync.RWMutex
+}
+
+var _ TemplateEngine = &Pongo2Engine{}
+
+// NewPongo2Engine returns a new pongo2 engine.
+func NewPongo2Engine() *Pongo2Engine {
+	return &Pongo2Engine{}
+}
+
+// pongo2Loader is the pongo2.TemplateLoader which loads the templates from the file system of the templates.
+type pongo2Loader struct {
+	fs  http.FileSystem
+	dir string
+}
+
+// Abs returns the path of a template relative to the Options.Directory.
+func (l pongo2Loader) Abs(base, name string) string {
+	if path.IsAbs(name) || base == "" {
+		return path.Clean(name)
+	}
+	return path.Join(path.Dir(base), name)
+}
+
+// Get returns the contents of a template.
+func (l pongo2Loader) Get(name string) (io.Reader, error) {
+	buf, err := readTemplateFile(l.fs, l.dir, name)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(buf), nil
+}
+
+// Load parses all the templates, in order to find the parse errors before the server's start.
+func (e *Pongo2Engine) Load(opt Options) error {
+	fs, dir := templatesFS(opt)
+	set := pongo2.NewSet(opt.Directory, pongo2Loader{fs: fs, dir: dir})
+	set.Debug = opt.IsDevelopment
+	names := make(map[string]string)
+
+	err := walkTemplates(opt, func(name string, filename string, contents []byte) error {
+		names[name] = filename
+		_, err := set.FromCache(filename)
+		return err
+	})
+
+	if err != nil {
+		if !opt.IsDevelopment {
+			panic(err)
+		}
+		return err
+	}
+
+	e.mu.Lock()
+	e.opt = opt
+	e.Set = set
+	e.names = names
+	e.mu.Unlock()
+	return nil
+}
+
+// Lookup returns true if a template with the given name exists.
+func (e *Pongo2Engine) Lookup(name string) bool {
+	e.mu.RLock()
+	_, ok := e.names[name]
+	e.mu.RUnlock()
+	return ok
+}
+
+// Execute executes the template with the given binding and writes the result to the writer.
+func (e *Pongo2Engine) Execute(w io.Writer, name string, binding interface{}, layout string) error {
+	return e.ExecuteWithFuncs(w, name, binding, layout, nil)
+}
+
+// ExecuteWithFuncs same as Execute but the funcs are added to, or override, the Options.Funcs for this execution only.
+func (e *Pongo2Engine) ExecuteWithFuncs(w io.Writer, name string, binding interface{}, layout string, funcs map[string]interface{}) error {
+	e.mu.RLock()
+	set, filename := e.Set, e.filename(name)
+	layoutFilename := e.filename(layout)
+	e.mu.RUnlock()
+
+	tmpl, err := set.FromCache(filename)
+	if err != nil {
+		return err
+	}
+
+	ctx := e.context(binding)
+	for k, v := range funcs {
+		ctx[k] = v
+	}
+	ctx["current"] = name
+	if layout == "" {
+		return tmpl.ExecuteWriter(ctx, w)
+	}
+
+	contents, err := tmpl.Execute(ctx)
+	if err != nil {
+		return err
+	}
+
+	layoutTmpl, err := set.FromCache(layoutFilename)
+	if err != nil {
+		return err
+	}
+	ctx["yield"] = pongo2.AsSafeValue(contents)
+	return layoutTmpl.ExecuteWriter(ctx, w)
+}
+
+// filename returns the file name of a template name, it expects the e.mu to be locked
+func (e *Pongo2Engine) filename(name string) string {
+	if filename, ok := e.names[name]; ok {
+		return filename
+	}
+	// maybe a file name with its extension
+	return filepath.ToSlash(name)
+}
+
+// context converts the binding to a pongo2.Context and adds the Options.Funcs to it
+func (e *Pongo2Engine) context(binding interface{}) pongo2.Context {
+	ctx := make(pongo2.Context)
+	for _, funcs := range e.opt.Funcs {
+		for k, v := range funcs {
+			ctx[k] = v
+		}
+	}
+
+	switch b := binding.(type) {
+	case nil:
+	case pongo2.Context:
+		ctx.Update(b)
+	case map[string]interface{}:
+		ctx.Update(b)
+	default:
+		ctx["data"] = b
+	}
+	return ctx
+}
@@ -0,0 +1,222 @@
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"sync"
+	"testing"
+
+	"github.com/kataras/iris/utils"
+	"github.com/valyala/fasthttp"
+)
+
+// templateTests the templates of each one of the built-in engines, they render the same output
+var templateTests = []struct {
+	name   string
+	engine func() TemplateEngine
+	files  map[string][]byte
+}{
+	{"html", func() TemplateEngine { return NewHTMLEngine() }, map[string][]byte{
+		"index.html":      []byte(`<h1>{{.Title}}</h1>`),
+		"users/show.html": []byte(`<p>{{.Title}} {{ current }}</p>`),
+		"layout.html":     []byte(`<main>{{ yield }}</main>`),
+		"notes.txt":       []byte(`not a template`),
+	}},
+	{"pongo2", func() TemplateEngine { return NewPongo2Engine() }, map[string][]byte{
+		"index.html":      []byte(`<h1>{{ Title }}</h1>`),
+		"users/show.html": []byte(`<p>{{ Title }} {{ current }}</p>`),
+		"layout.html":     []byte(`<main>{{ yield }}</main>`),
+		"notes.txt":       []byte(`not a template`),
+	}},
+	{"handlebars", func() TemplateEngine { return NewHandlebarsEngine() }, map[string][]byte{
+		"index.html":      []byte(`<h1>{{Title}}</h1>`),
+		"users/show.html": []byte(`<p>{{Title}} {{current}}</p>`),
+		"layout.html":     []byte(`<main>{{yield}}</main>`),
+		"notes.txt":       []byte(`not a template`),
+	}},
+}
+
+// newTestRender returns a Render which loads the templates from the files
+func newTestRender(engine TemplateEngine, files map[string][]byte, options ...Options) *Render {
+	var opt Options
+	if len(options) > 0 {
+		opt = options[0]
+	}
+	opt.Engine = engine
+	opt.FileSystem = utils.NewMapFS(files)
+	opt.Directory = "/"
+	return New(opt)
+}
+
+func renderHTML(t *testing.T, r *Render, name string, binding interface{}, htmlOpt ...HTMLOptions) *fasthttp.RequestCtx {
+	t.Helper()
+	ctx := new(fasthttp.RequestCtx)
+	if err := r.HTML(ctx, fasthttp.StatusOK, name, binding, htmlOpt...); err != nil {
+		t.Fatalf("unexpected error while rendering %s: %v", name, err)
+	}
+	return ctx
+}
+
+func TestTemplateEngines(t *testing.T) {
+	binding := map[string]interface{}{"Title": "Hello"}
+
+	for _, tt := range templateTests {
+		r := newTestRender(tt.engine(), tt.files)
+
+		for _, name := range []string{"index", "users/show", "layout"} {
+			if !r.Engine().Lookup(name) {
+				t.Fatalf("%s: expected the template %s to be loaded", tt.name, name)
+			}
+		}
+		if r.Engine().Lookup("notes") {
+			t.Fatalf("%s: expected the files without the template extension to be skipped", tt.name)
+		}
+
+		ctx := renderHTML(t, r, "index", binding)
+		if body := string(ctx.Response.Body()); body != "<h1>Hello</h1>" {
+			t.Fatalf("%s: unexpected body %q", tt.name, body)
+		}
+		if contentType := string(ctx.Response.Header.ContentType()); contentType != "text/html; charset=UTF-8" {
+			t.Fatalf("%s: unexpected content type %q", tt.name, contentType)
+		}
+
+		ctx = renderHTML(t, r, "users/show", binding, HTMLOptions{Layout: "layout"})
+		if body := string(ctx.Response.Body()); body != "<main><p>Hello users/show</p></main>" {
+			t.Fatalf("%s: unexpected body with layout %q", tt.name, body)
+		}
+	}
+}
+
+func TestTemplateEnginesNotFound(t *testing.T) {
+	for _, tt := range templateTests {
+		r := newTestRender(tt.engine(), tt.files)
+		ctx := new(fasthttp.RequestCtx)
+		if err := r.HTML(ctx, fasthttp.StatusOK, "missing", nil); err == nil {
+			t.Fatalf("%s: expected an error for a missing template", tt.name)
+		}
+		if status := ctx.Response.StatusCode(); status != fasthttp.StatusInternalServerError {
+			t.Fatalf("%s: expected status code %d but got %d", tt.name, fasthttp.StatusInternalServerError, status)
+		}
+	}
+}
+
+func TestTemplateEnginesParseError(t *testing.T) {
+	broken := map[string][]byte{"index.html": []byte(`{{ if }`)}
+	engines := []TemplateEngine{NewHTMLEngine(), NewPongo2Engine(), NewHandlebarsEngine()}
+
+	for _, engine := range engines {
+		// in production the parse errors panic
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%T: expected a panic on a parse error", engine)
+				}
+			}()
+			newTestRender(engine, broken)
+		}()
+	}
+
+	for _, engine := range []TemplateEngine{NewHTMLEngine(), NewPongo2Engine(), NewHandlebarsEngine()} {
+		// on development the error is rendered instead
+		r := newTestRender(engine, broken, Options{IsDevelopment: true})
+		ctx := new(fasthttp.RequestCtx)
+		if err := r.HTML(ctx, fasthttp.StatusOK, "index", nil); err == nil {
+			t.Fatalf("%T: expected the parse error on development", engine)
+		}
+	}
+}
+
+func TestTemplateEnginesRequestFuncs(t *testing.T) {
+	tests := []struct {
+		engine TemplateEngine
+		index  string
+	}{
+		{NewHTMLEngine(), `{{ translate "hello" }} {{ greet .Name }}`},
+		{NewPongo2Engine(), `{{ translate("hello") }} {{ greet(Name) }}`},
+		{NewHandlebarsEngine(), `{{translate "hello"}} {{greet Name}}`},
+	}
+
+	translate := func(lang string) func(string, ...interface{}) string {
+		return func(format string, args ...interface{}) string { return lang + ":" + format }
+	}
+	greet := func(prefix string) func(string) string {
+		return func(name string) string { return prefix + " " + name }
+	}
+
+	for _, tt := range tests {
+		r := newTestRender(tt.engine, map[string][]byte{"index.html": []byte(tt.index)}, Options{
+			Funcs: []template.FuncMap{{"translate": translate("en"), "greet": greet("hi")}},
+		})
+		binding := map[string]interface{}{"Name": "kataras"}
+
+		if body := string(renderHTML(t, r, "index", binding).Response.Body()); body != "en:hello hi kataras" {
+			t.Fatalf("%T: expected the default funcs but got %q", tt.engine, body)
+		}
+
+		for _, lang := range []string{"el", "de"} {
+			ctx := new(fasthttp.RequestCtx)
+			SetRequestFunc(ctx, "translate", translate(lang))
+			SetRequestFunc(ctx, "greet", greet("hello "+lang))
+			if err := r.HTML(ctx, fasthttp.StatusOK, "index", binding); err != nil {
+				t.Fatalf("%T: unexpected error: %v", tt.engine, err)
+			}
+			if expected, body := lang+":hello hello "+lang+" kataras", string(ctx.Response.Body()); body != expected {
+				t.Fatalf("%T: expected %q but got %q", tt.engine, expected, body)
+			}
+		}
+	}
+}
+
+func TestHTMLEngineRequestFuncsReuse(t *testing.T) {
+	engine := NewHTMLEngine()
+	files := map[string][]byte{
+		"index.html":  []byte(`{{ translate "hello" }}`),
+		"layout.html": []byte(`<main>{{ yield }}</main>`),
+	}
+	r := newTestRender(engine, files, Options{
+		Funcs: []template.FuncMap{{"translate": func(s string) string { return "en:" + s }}},
+	})
+
+	render := func(lang string, layout string) (string, error) {
+		ctx := new(fasthttp.RequestCtx)
+		SetRequestFunc(ctx, "translate", func(s string) string { return lang + ":" + s })
+		err := r.HTML(ctx, fasthttp.StatusOK, "index", nil, HTMLOptions{Layout: layout})
+		return string(ctx.Response.Body()), err
+	}
+
+	for i := 0; i < 10; i++ {
+		if body, err := render("el", ""); err != nil || body != "el:hello" {
+			t.Fatalf("expected el:hello but got %q, %v", body, err)
+		}
+	}
+	// the executions one after the other reuse the same clone
+	if n := len(engine.clones); n != 1 {
+		t.Fatalf("expected one clone of the templates but got %d", n)
+	}
+	if body := string(renderHTML(t, r, "index", nil).Response.Body()); body != "en:hello" {
+		t.Fatalf("expected the default func but got %q", body)
+	}
+
+	// the concurrent executions don't see the funcs of each other
+	langs := []string{"el", "de", "fr", "it"}
+	errs := make(chan error, len(langs)*20)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func(lang string) {
+			defer wg.Done()
+			body, err := render(lang, "layout")
+			if expected := "<main>" + lang + ":hello</main>"; err == nil && body != expected {
+				err = fmt.Errorf("expected %q but got %q", expected, body)
+			}
+			errs <- err
+		}(langs[i%len(langs)])
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -29,6 +29,7 @@ package iris
 
 import (
 	"net/http/pprof"
+	"strings"
 	"sync"
 
 	"github.com/kataras/iris/utils"
@@ -38,6 +39,8 @@ import (
 const (
 	// DefaultProfilePath is the default path for the web pprof '/debug/pprof'
 	DefaultProfilePath = "/debug/pprof"
+	// DefaultMaxForwardDepth is the default maximum number of nested ctx.Forward/ctx.Exec calls per request
+	DefaultMaxForwardDepth = 10
 
 	// ParameterStartByte is very used on the node, it's just contains the byte for the ':' rune/char
 	ParameterStartByte = byte(':')
@@ -92,6 +95,14 @@ type router struct {
 	//it's true when optimize already ran
 	optimized bool
 	mu        sync.Mutex
+	// fallbacks serve the GET requests which are not found under their path prefixes, look Party.SPA
+	fallbacks []notFoundFallback
+}
+
+// notFoundFallback is a handler which serves the not found requests under a path prefix, instead of the http error 404
+type notFoundFallback struct {
+	prefix     string
+	middleware Middleware
 }
 
 // methodMatchCorsFunc is sets the methodMatch when cors enabled (look router.optimize), it's allowing OPTIONS method to all other methods except GET
@@ -184,10 +195,43 @@ func (r *router) optimize() {
 func (r *router) notFound(reqCtx *fasthttp.RequestCtx) {
 	ctx := r.errorPool.Get().(*Context)
 	ctx.Reset(reqCtx)
-	ctx.NotFound()
+	if middleware := r.fallback(ctx); middleware != nil {
+		ctx.middleware = middleware
+		ctx.Do()
+	} else {
+		// no route is matched, the done handlers of the station are executed
+		ctx.done = r.GardenParty.done
+		ctx.NotFound()
+	}
+	ctx.end()
 	r.errorPool.Put(ctx)
 }
 
+// addFallback registers the handlers which serve the not found GET and HEAD requests under the path prefix
+func (r *router) addFallback(prefix string, middleware Middleware) {
+	r.fallbacks = append(r.fallbacks, notFoundFallback{prefix: strings.TrimSuffix(prefix, Slash), middleware: middleware})
+}
+
+// fallback returns the fallback handlers of the not found request, the ones of the longest path prefix, or nil
+func (r *router) fallback(ctx *Context) Middleware {
+	if len(r.fallbacks) == 0 {
+		return nil
+	}
+	if method := ctx.MethodString(); method != MethodGet && method != MethodHead {
+		return nil
+	}
+
+	reqPath := ctx.PathString()
+	var middleware Middleware
+	longest := -1
+	for _, f := range r.fallbacks {
+		if len(f.prefix) > longest && (f.prefix == "" || reqPath == f.prefix || strings.HasPrefix(reqPath, f.prefix+Slash)) {
+			middleware, longest = f.middleware, len(f.prefix)
+		}
+	}
+	return middleware
+}
+
 //************************************************************************************
 // serveFunc & serveDomainFunc selected on router.optimize, which runs before station's listen
 // they are not used directly.
@@ -0,0 +1,84 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS BE LIABLE FOR ANY
+// DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package server
+
+import (
+	"net"
+	"os"
+	"strconv"
+)
+
+const (
+	// ListenFDsEnv the environment variable of the number of the inherited listeners, by the systemd socket activation or the graceful re-exec
+	ListenFDsEnv = "LISTEN_FDS"
+	// ListenPIDEnv the environment variable of the process id which the inherited listeners are passed to
+	ListenPIDEnv = "LISTEN_PID"
+	// listenFDsStart the file descriptor of the first inherited listener, after the stdin, stdout and stderr
+	listenFDsStart = 3
+)
+
+// InheritedListeners returns the listeners which are passed to this process by the systemd socket activation (LISTEN_FDS and LISTEN_PID)
+// or by the re-exec of a parent process, look graceful.Server.Upgrade, in the order of their file descriptors, starting from 3.
+// The LISTEN_PID, if set, must be the process id of this process, the re-exec doesn't set it because the child's id is not known before its start.
+// The environment variables are unset, so the child processes don't inherit them.
+// Returns nil if there are no inherited listeners.
+//
+// Use them by the Config's Listener, i.e iris.ListenAll(server.Config{Listener: listeners[0]}), or by the iris.ListenInherited
+func InheritedListeners() ([]net.Listener, error) {
+	fds := os.Getenv(ListenFDsEnv)
+	if fds == "" {
+		return nil, nil
+	}
+	if pid := os.Getenv(ListenPIDEnv); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		// they are passed to an other process, i.e our parent
+		return nil, nil
+	}
+	os.Unsetenv(ListenFDsEnv)
+	os.Unsetenv(ListenPIDEnv)
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 0 {
+		return nil, ErrServerInheritedListener.Format(ListenFDsEnv+"="+fds, "not a valid number")
+	}
+
+	listeners := make([]net.Listener, 0, n)
+	for fd := listenFDsStart; fd < listenFDsStart+n; fd++ {
+		f := os.NewFile(uintptr(fd), "listener-"+strconv.Itoa(fd))
+		l, err := net.FileListener(f) // it's a dup, the original is closed
+		f.Close()
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, ErrServerInheritedListener.Format(strconv.Itoa(fd), err.Error())
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS BE LIABLE FOR ANY
+// DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+//go:build !windows
+// +build !windows
+
+package server
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+)
+
+// inheritedHelperEnv marks the process which is started by the TestInheritedListeners with an inherited listener
+const inheritedHelperEnv = "IRIS_TEST_INHERITED_HELPER"
+
+func TestInheritedListenersEnv(t *testing.T) {
+	defer os.Unsetenv(ListenFDsEnv)
+	defer os.Unsetenv(ListenPIDEnv)
+
+	os.Unsetenv(ListenFDsEnv)
+	if listeners, err := InheritedListeners(); listeners != nil || err != nil {
+		t.Fatalf("expected no listeners without the %s but got %v, %v", ListenFDsEnv, listeners, err)
+	}
+
+	// the listeners of an other process are ignored and they are kept for it
+	os.Setenv(ListenFDsEnv, "1")
+	os.Setenv(ListenPIDEnv, strconv.Itoa(os.Getpid()+1))
+	if listeners, err := InheritedListeners(); listeners != nil || err != nil {
+		t.Fatalf("expected no listeners of an other process but got %v, %v", listeners, err)
+	}
+	if os.Getenv(ListenFDsEnv) != "1" {
+		t.Fatalf("expected the %s of an other process to be kept", ListenFDsEnv)
+	}
+
+	os.Setenv(ListenPIDEnv, strconv.Itoa(os.Getpid()))
+	os.Setenv(ListenFDsEnv, "0")
+	if listeners, err := InheritedListeners(); len(listeners) != 0 || err != nil {
+		t.Fatalf("expected no listeners of LISTEN_FDS=0 but got %v, %v", listeners, err)
+	}
+	if os.Getenv(ListenFDsEnv) != "" || os.Getenv(ListenPIDEnv) != "" {
+		t.Fatal("expected the environment variables to be unset")
+	}
+
+	os.Setenv(ListenFDsEnv, "x")
+	if _, err := InheritedListeners(); err == nil {
+		t.Fatal("expected the error of an invalid LISTEN_FDS")
+	}
+}
+
+func TestInheritedListeners(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := l.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+
+	// the helper process inherits the listener as the file descriptor 3
+	var out bytes.Buffer
+	cmd := exec.Command(os.Args[0], "-test.run=^TestInheritedListenersHelper$")
+	cmd.Env = append(os.Environ(), inheritedHelperEnv+"=1", ListenFDsEnv+"=1")
+	cmd.ExtraFiles = []*os.File{f}
+	cmd.Stdout, cmd.Stderr = &out, &out
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	// only the helper accepts the connections now
+	f.Close()
+	l.Close()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(conn)
+	conn.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("the helper process failed: %v\n%s", err, out.String())
+	}
+	if string(body) != "inherited "+addr {
+		t.Fatalf("expected the response of the inherited listener but got %q", body)
+	}
+}
+
+// TestInheritedListenersHelper is the process which is started by the TestInheritedListeners, it serves one connection of its inherited listener
+func TestInheritedListenersHelper(t *testing.T) {
+	if os.Getenv(inheritedHelperEnv) != "1" {
+		return
+	}
+
+	listeners, err := InheritedListeners()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listeners) != 1 {
+		t.Fatalf("expected one inherited listener but got %d", len(listeners))
+	}
+	if os.Getenv(ListenFDsEnv) != "" {
+		t.Fatalf("expected the %s to be unset", ListenFDsEnv)
+	}
+
+	conn, err := listeners[0].Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Write([]byte("inherited " + listeners[0].Addr().String()))
+	conn.Close()
+	listeners[0].Close()
+}
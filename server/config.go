@@ -27,7 +27,13 @@
 
 package server
 
-import "os"
+import (
+	"net"
+	"os"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
 
 // Config used inside server for listening
 // all options are exported.
@@ -38,4 +44,125 @@ type Config struct {
 	KeyFile       string
 	// Mode this is for unix only
 	Mode os.FileMode
+	// Listener if not nil the server serves this listener, which is created by the caller, instead of listening to the ListeningAddr,
+	// it's served with TLS if the CertFile and KeyFile are set
+	Listener net.Listener
+	// TLS the advanced tls options, the certificates which are selected by the SNI, their reload, the client certificates (mutual tls),
+	// the minimum version and the cipher suites, if not nil the server listens with tls
+	TLS *TLSConfig
+	// Tuning the options of the fasthttp server, the timeouts, the limits and the buffers
+	Tuning
+}
+
+// Tuning the options of the fasthttp server, the zero values are the fasthttp's defaults
+type Tuning struct {
+	// Name the value of the 'Server' response header
+	// Default is "iris"
+	Name string
+	// ReadTimeout the maximum duration for reading the full request, including the body
+	// Default is 0, unlimited
+	ReadTimeout time.Duration
+	// WriteTimeout the maximum duration for writing the full response, including the body
+	// Default is 0, unlimited
+	WriteTimeout time.Duration
+	// MaxKeepaliveDuration the maximum duration of a keep-alive connection, the idle connections are closed after that
+	// Default is 0, unlimited
+	MaxKeepaliveDuration time.Duration
+	// DisableKeepalive closes the connection after each response
+	// Default is false
+	DisableKeepalive bool
+	// Concurrency the maximum number of the concurrent connections
+	// Default is 0, the fasthttp.DefaultConcurrency (256 * 1024)
+	Concurrency int
+	// MaxConnsPerIP the maximum number of the concurrent connections per client ip
+	// Default is 0, unlimited
+	MaxConnsPerIP int
+	// MaxRequestsPerConn the maximum number of the requests per connection, the connection is closed after the last request
+	// Default is 0, unlimited
+	MaxRequestsPerConn int
+	// ReadBufferSize the buffer size of the requests per connection, it's also the limit of the request headers
+	// Default is 0, the fasthttp's default 4096
+	ReadBufferSize int
+	// WriteBufferSize the buffer size of the responses per connection
+	// Default is 0, the fasthttp's default 4096
+	WriteBufferSize int
+	// ReduceMemoryUsage reduces the memory usage in favor of the cpu usage, useful for many idle keep-alive connections
+	// Default is false
+	ReduceMemoryUsage bool
+}
+
+// Merge returns the tuning with its zero fields set from the defaults, i.e the IrisConfig's Server
+func (t Tuning) Merge(defaults Tuning) Tuning {
+	if t.Name == "" {
+		t.Name = defaults.Name
+	}
+	if t.ReadTimeout == 0 {
+		t.ReadTimeout = defaults.ReadTimeout
+	}
+	if t.WriteTimeout == 0 {
+		t.WriteTimeout = defaults.WriteTimeout
+	}
+	if t.MaxKeepaliveDuration == 0 {
+		t.MaxKeepaliveDuration = defaults.MaxKeepaliveDuration
+	}
+	if t.Concurrency == 0 {
+		t.Concurrency = defaults.Concurrency
+	}
+	if t.MaxConnsPerIP == 0 {
+		t.MaxConnsPerIP = defaults.MaxConnsPerIP
+	}
+	if t.MaxRequestsPerConn == 0 {
+		t.MaxRequestsPerConn = defaults.MaxRequestsPerConn
+	}
+	if t.ReadBufferSize == 0 {
+		t.ReadBufferSize = defaults.ReadBufferSize
+	}
+	if t.WriteBufferSize == 0 {
+		t.WriteBufferSize = defaults.WriteBufferSize
+	}
+	t.DisableKeepalive = t.DisableKeepalive || defaults.DisableKeepalive
+	t.ReduceMemoryUsage = t.ReduceMemoryUsage || defaults.ReduceMemoryUsage
+	return t
+}
+
+// Validate returns an error if an option of the tuning is invalid, the durations, the limits and the buffer sizes cannot be negative
+func (t Tuning) Validate() error {
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{{"ReadTimeout", t.ReadTimeout}, {"WriteTimeout", t.WriteTimeout}, {"MaxKeepaliveDuration", t.MaxKeepaliveDuration}}
+	for _, d := range durations {
+		if d.value < 0 {
+			return ErrServerTuning.Format(d.name, d.value)
+		}
+	}
+
+	ints := []struct {
+		name  string
+		value int
+	}{{"Concurrency", t.Concurrency}, {"MaxConnsPerIP", t.MaxConnsPerIP}, {"MaxRequestsPerConn", t.MaxRequestsPerConn},
+		{"ReadBufferSize", t.ReadBufferSize}, {"WriteBufferSize", t.WriteBufferSize}}
+	for _, i := range ints {
+		if i.value < 0 {
+			return ErrServerTuning.Format(i.name, i.value)
+		}
+	}
+	return nil
+}
+
+// apply sets the options of the tuning to the fasthttp server
+func (t Tuning) apply(s *fasthttp.Server) {
+	if t.Name != "" {
+		s.Name = t.Name
+	}
+	s.ReadTimeout = t.ReadTimeout
+	s.WriteTimeout = t.WriteTimeout
+	s.MaxKeepaliveDuration = t.MaxKeepaliveDuration
+	s.DisableKeepalive = t.DisableKeepalive
+	s.Concurrency = t.Concurrency
+	s.MaxConnsPerIP = t.MaxConnsPerIP
+	s.MaxRequestsPerConn = t.MaxRequestsPerConn
+	s.ReadBufferSize = t.ReadBufferSize
+	s.WriteBufferSize = t.WriteBufferSize
+	s.ReduceMemoryUsage = t.ReduceMemoryUsage
 }
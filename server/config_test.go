@@ -0,0 +1,116 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS BE LIABLE FOR ANY
+// DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package server
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestTuningMerge(t *testing.T) {
+	defaults := Tuning{Name: "iris", ReadTimeout: time.Second, Concurrency: 100, MaxConnsPerIP: 10, DisableKeepalive: true}
+	tuning := Tuning{ReadTimeout: 2 * time.Second, WriteBufferSize: 8192, ReduceMemoryUsage: true}.Merge(defaults)
+
+	expected := Tuning{Name: "iris", ReadTimeout: 2 * time.Second, Concurrency: 100, MaxConnsPerIP: 10, WriteBufferSize: 8192,
+		DisableKeepalive: true, ReduceMemoryUsage: true}
+	if tuning != expected {
+		t.Fatalf("expected the merged tuning %+v but got %+v", expected, tuning)
+	}
+}
+
+func TestTuningValidate(t *testing.T) {
+	if err := DefaultTuning().Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]Tuning{
+		"ReadTimeout":          {ReadTimeout: -time.Second},
+		"MaxKeepaliveDuration": {MaxKeepaliveDuration: -1},
+		"Concurrency":          {Concurrency: -1},
+		"WriteBufferSize":      {WriteBufferSize: -1},
+	}
+	for name, tuning := range tests {
+		if err := tuning.Validate(); err == nil || !strings.Contains(err.Error(), name) {
+			t.Fatalf("%s: expected the error of the invalid option but got %v", name, err)
+		}
+	}
+
+	// the server is not opened with an invalid tuning
+	s := New(Config{ListeningAddr: "127.0.0.1:0", Tuning: Tuning{Concurrency: -1}})
+	if err := s.OpenServer(); err == nil || s.IsListening() {
+		t.Fatalf("expected the error of the invalid tuning but got %v", err)
+	}
+}
+
+func TestNewTuning(t *testing.T) {
+	s := New(Config{ListeningAddr: "127.0.0.1:0", Tuning: Tuning{ReadTimeout: time.Second, Concurrency: 10, MaxRequestsPerConn: 1}})
+	if s.Server.Name != DefaultServerName {
+		t.Fatalf("expected the default server name but got %q", s.Server.Name)
+	}
+	if s.Server.ReadTimeout != time.Second || s.Server.Concurrency != 10 || s.Server.MaxRequestsPerConn != 1 {
+		t.Fatalf("expected the tuning to be applied to the fasthttp server but got %+v", s.Server)
+	}
+
+	s.Config.Name = "myserver"
+	s = New(s.Config)
+	s.SetHandler(func(reqCtx *fasthttp.RequestCtx) {
+		reqCtx.WriteString("hello")
+	})
+	if err := s.OpenServer(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.CloseServer()
+
+	conn, err := net.Dial("tcp", s.Listener().Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if server := resp.Header.Get("Server"); server != "myserver" {
+		t.Fatalf("expected the Server header myserver but got %q", server)
+	}
+	// the MaxRequestsPerConn closes the connection after the first response
+	if !resp.Close {
+		t.Fatal("expected the connection to be closed after the MaxRequestsPerConn")
+	}
+}
@@ -38,8 +38,18 @@ var (
 	ErrServerOptionsMissing = errors.New("You have to pass iris.ServerOptions")
 	// ErrServerTLSOptionsMissing returns an error with message: 'You have to set CertFile and KeyFile to iris.ServerOptions before ListenTLS'
 	ErrServerTLSOptionsMissing = errors.New("You have to set CertFile and KeyFile to iris.ServerOptions before ListenTLS")
+	// ErrServerTLSCertificate returns an error with message: 'Cannot load the certificate +certfile: +specific error'
+	ErrServerTLSCertificate = errors.New("Cannot load the certificate %s: %s")
+	// ErrServerTLSClientCA returns an error with message: 'Cannot load the client CAs from +file: +specific error'
+	ErrServerTLSClientCA = errors.New("Cannot load the client CAs from %s: %s")
+	// ErrServerTuning returns an error with message: 'Invalid server option +name: +value'
+	ErrServerTuning = errors.New("Invalid server option %s: %v")
+	// ErrServerInheritedListener returns an error with message: 'Cannot use the inherited listener +fd: +specific error'
+	ErrServerInheritedListener = errors.New("Cannot use the inherited listener %s: %s")
 	// ErrServerIsClosed returns an error with message: 'Can't close the server, propably is already closed or never started'
 	ErrServerIsClosed = errors.New("Can't close the server, propably is already closed or never started")
+	// ErrServerNoReloadableCertificates returns an error with message: 'The server has no certificates to reload, it's not started with a TLSConfig'
+	ErrServerNoReloadableCertificates = errors.New("The server has no certificates to reload, it's not started with a TLSConfig")
 	// ErrServerUnknown returns an error with message: 'Unknown reason from Server, please report this as bug!'
 	ErrServerUnknown = errors.New("Unknown reason from Server, please report this as bug!")
 	// ErrParsedAddr returns an error with message: 'ListeningAddr error, for TCP and UDP, the syntax of ListeningAddr is host:port, like 127.0.0.1:8080.
@@ -28,9 +28,11 @@
 package server
 
 import (
+	"crypto/tls"
 	"net"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/valyala/fasthttp"
 )
@@ -51,6 +53,11 @@ type Server struct {
 	started  bool
 	tls      bool
 	handler  fasthttp.RequestHandler
+	// certs the certificates of the tls server, if it's started by the Config.TLS
+	certs *certStore
+	// err is the error which stopped the serve, look Err
+	err error
+	mu  sync.Mutex
 }
 
 // New returns a pointer to a Server object, and set it's options if any,  nothing more
@@ -59,14 +66,26 @@ func New(opt ...Config) *Server {
 	if opt != nil && len(opt) > 0 {
 		s.Config = opt[0]
 	}
-	s.Config.ListeningAddr = parseAddr(s.Config.ListeningAddr)
+	if s.Config.Listener != nil {
+		if s.Config.ListeningAddr == "" {
+			s.Config.ListeningAddr = s.Config.Listener.Addr().String()
+		}
+	} else if s.Config.Mode == 0 { // the unix socket's addr is a file
+		s.Config.ListeningAddr = parseAddr(s.Config.ListeningAddr)
+	}
+	s.Config.Tuning.apply(s.Server)
 
 	return s
 }
 
 // DefaultConfig returns the default options for the server
 func DefaultConfig() Config {
-	return Config{DefaultServerAddr, "", "", 0}
+	return Config{ListeningAddr: DefaultServerAddr, Tuning: DefaultTuning()}
+}
+
+// DefaultTuning returns the default options of the fasthttp server, the fasthttp's defaults with the iris' server name
+func DefaultTuning() Tuning {
+	return Tuning{Name: DefaultServerName}
 }
 
 // DefaultServerSecureOptions does nothing now
@@ -101,6 +120,31 @@ func (s *Server) Listener() net.Listener {
 	return s.listener
 }
 
+// Err returns the error which stopped the server from serving its listener, i.e the accept failed,
+// it's nil while the server is serving and after the server is closed by the CloseServer
+func (s *Server) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// serve serves the l with the serveFunc, it's blocking, the error is kept for the Err.
+// The l is passed by the caller, the s.listener may be replaced by a next listen (i.e a retry) before the goroutine starts
+func (s *Server) serve(l net.Listener, serveFunc func(net.Listener) error) {
+	err := serveFunc(l)
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// openListener returns the Config.Listener if not nil, otherwise it listens to the ListeningAddr
+func (s *Server) openListener(network string) (net.Listener, error) {
+	if s.Config.Listener != nil {
+		return s.Config.Listener, nil
+	}
+	return net.Listen(network, s.Config.ListeningAddr)
+}
+
 //Serve just serves a listener, it is a blocking action, plugin.PostListen is not fired here.
 func (s *Server) Serve(l net.Listener) error {
 	s.listener = l
@@ -114,7 +158,7 @@ func (s *Server) listen() (err error) {
 		err = ErrServerAlreadyStarted.Return()
 		return
 	}
-	s.listener, err = net.Listen("tcp", s.Config.ListeningAddr)
+	s.listener, err = s.openListener("tcp")
 
 	if err != nil {
 		err = ErrServerPortAlreadyUsed.Return()
@@ -122,7 +166,7 @@ func (s *Server) listen() (err error) {
 	}
 
 	//Non-block way here because I want the plugin's PostListen ability...
-	go s.Server.Serve(s.listener)
+	go s.serve(s.listener, s.Server.Serve)
 
 	s.started = true
 	s.tls = false
@@ -138,19 +182,34 @@ func (s *Server) listenTLS() (err error) {
 		return
 	}
 
-	if s.Config.CertFile == "" || s.Config.KeyFile == "" {
+	if s.Config.TLS == nil && (s.Config.CertFile == "" || s.Config.KeyFile == "") {
 		err = ErrServerTLSOptionsMissing.Return()
 		return
 	}
 
-	s.listener, err = net.Listen("tcp", s.Config.ListeningAddr)
+	var tlsConfig *tls.Config
+	if s.Config.TLS != nil {
+		if tlsConfig, err = s.newTLSConfig(); err != nil {
+			return
+		}
+	}
+
+	s.listener, err = s.openListener("tcp")
 
 	if err != nil {
+		if s.certs != nil {
+			s.certs.close()
+		}
 		err = ErrServerPortAlreadyUsed.Return()
 		return
 	}
 
-	go s.Server.ServeTLS(s.listener, s.Config.CertFile, s.Config.KeyFile)
+	if tlsConfig != nil {
+		s.listener = tls.NewListener(s.listener, tlsConfig)
+		go s.serve(s.listener, s.Server.Serve)
+	} else {
+		go s.serve(s.listener, func(l net.Listener) error { return s.Server.ServeTLS(l, s.Config.CertFile, s.Config.KeyFile) })
+	}
 
 	s.started = true
 	s.tls = true
@@ -169,24 +228,28 @@ func (s *Server) listenUnix() (err error) {
 	mode := s.Config.Mode
 
 	//this code is from fasthttp ListenAndServeUNIX, I extracted it because we need the tcp.Listener
-	if errOs := os.Remove(s.Config.ListeningAddr); errOs != nil && !os.IsNotExist(errOs) {
-		err = ErrServerRemoveUnix.Format(s.Config.ListeningAddr, errOs.Error())
-		return
+	if s.Config.Listener == nil {
+		if errOs := os.Remove(s.Config.ListeningAddr); errOs != nil && !os.IsNotExist(errOs) {
+			err = ErrServerRemoveUnix.Format(s.Config.ListeningAddr, errOs.Error())
+			return
+		}
 	}
-	s.listener, err = net.Listen("unix", s.Config.ListeningAddr)
+	s.listener, err = s.openListener("unix")
 
 	if err != nil {
 		err = ErrServerPortAlreadyUsed.Return()
 		return
 	}
 
-	if err = os.Chmod(s.Config.ListeningAddr, mode); err != nil {
-		err = ErrServerChmod.Format(mode, s.Config.ListeningAddr, err.Error())
-		return
+	if s.Config.Listener == nil {
+		if err = os.Chmod(s.Config.ListeningAddr, mode); err != nil {
+			err = ErrServerChmod.Format(mode, s.Config.ListeningAddr, err.Error())
+			return
+		}
 	}
 
 	s.Server.Handler = s.handler
-	go s.Server.Serve(s.listener)
+	go s.serve(s.listener, s.Server.Serve)
 
 	s.started = true
 	s.tls = false
@@ -198,7 +261,11 @@ func (s *Server) listenUnix() (err error) {
 // OpenServer opens/starts/runs/listens (to) the server, listenTLS if Cert && Key is registed, listenUnix if Mode is registed, otherwise listen
 // instead of return an error this is panics on any server's error
 func (s *Server) OpenServer() (err error) {
-	if s.Config.CertFile != "" && s.Config.KeyFile != "" {
+	if err = s.Config.Tuning.Validate(); err != nil {
+		return
+	}
+
+	if s.Config.TLS != nil || s.Config.CertFile != "" && s.Config.KeyFile != "" {
 		err = s.listenTLS()
 	} else if s.Config.Mode > 0 {
 		err = s.listenUnix()
@@ -216,6 +283,10 @@ func (s *Server) CloseServer() error {
 		return ErrServerIsClosed.Return()
 	}
 
+	s.started = false
+	if s.certs != nil {
+		s.certs.close()
+	}
 	if s.listener != nil {
 		return s.listener.Close()
 	}
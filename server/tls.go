@@ -0,0 +1,281 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS BE LIABLE FOR ANY
+// DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Certificate a certificate and its key, both pem encoded files
+type Certificate struct {
+	CertFile string
+	KeyFile  string
+}
+
+// TLSConfig the advanced tls options of a Config, look Config.TLS
+type TLSConfig struct {
+	// Certificates the certificates of the server, the certificate of a connection is selected by the server name (SNI)
+	// which the client sends, by the DNS names of the certificates, wildcards included.
+	// The first is the default, it's used by the clients which don't send a known server name.
+	// The Config's CertFile and KeyFile, if not empty, are the first certificate
+	Certificates []Certificate
+	// ReloadInterval if > 0 the files of the certificates are checked at this interval
+	// and the certificates are reloaded when the files change, without restart, look Server.ReloadCertificates
+	// Default is 0, no reload
+	ReloadInterval time.Duration
+	// ReloadError is called when a changed certificate cannot be loaded, the previous certificate is kept
+	// Default is nil
+	ReloadError func(error)
+	// ClientCAFile a pem encoded file of the CAs which sign the client certificates, if not empty the client certificates are verified (mutual tls)
+	ClientCAFile string
+	// ClientAuth the policy of the client certificates
+	// Default is tls.NoClientCert, or tls.RequireAndVerifyClientCert if the ClientCAFile is not empty
+	ClientAuth tls.ClientAuthType
+	// MinVersion the minimum tls version
+	// Default is tls.VersionTLS12
+	MinVersion uint16
+	// CipherSuites the cipher suites of the tls versions up to 1.2, by preference
+	// Default is nil, the crypto/tls's defaults
+	CipherSuites []uint16
+}
+
+// certStore keeps the loaded certificates of a server, it selects them by the server name and reloads them
+type certStore struct {
+	files []Certificate
+	mu    sync.RWMutex
+	certs []*tls.Certificate
+	// names the certificates by their lowercase DNS names, i.e "example.com" or "*.example.com"
+	names   map[string]*tls.Certificate
+	modTime []time.Time
+	stop    chan struct{}
+	// reloadMu serializes the reloads of the watch and the Server.ReloadCertificates
+	reloadMu sync.Mutex
+}
+
+// newCertStore loads the certificates
+func newCertStore(files []Certificate) (*certStore, error) {
+	c := &certStore{files: files, certs: make([]*tls.Certificate, len(files)), modTime: make([]time.Time, len(files))}
+	for i := range files {
+		if _, err := c.load(i); err != nil {
+			return nil, err
+		}
+	}
+	c.index()
+	return c, nil
+}
+
+// load (re)loads the i certificate, returns false if its files are not changed
+func (c *certStore) load(i int) (bool, error) {
+	f := c.files[i]
+	modTime := latestModTime(f.CertFile, f.KeyFile)
+	if c.certs[i] != nil && !modTime.After(c.modTime[i]) {
+		return false, nil
+	}
+	cert, err := tls.LoadX509KeyPair(f.CertFile, f.KeyFile)
+	if err == nil {
+		cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	}
+	if err != nil {
+		// the same files are not loaded again, until they change
+		c.modTime[i] = modTime
+		return false, ErrServerTLSCertificate.Format(f.CertFile, err.Error())
+	}
+
+	c.mu.Lock()
+	c.certs[i] = &cert
+	c.modTime[i] = modTime
+	c.mu.Unlock()
+	return true, nil
+}
+
+// index maps the certificates by their DNS names, the first certificate of a name wins
+func (c *certStore) index() {
+	c.mu.Lock()
+	c.names = make(map[string]*tls.Certificate)
+	for _, cert := range c.certs {
+		// a copy, the append shouldn't write to the backing array of the Leaf's DNSNames
+		names := append([]string(nil), cert.Leaf.DNSNames...)
+		if cert.Leaf.Subject.CommonName != "" {
+			names = append(names, cert.Leaf.Subject.CommonName)
+		}
+		for _, name := range names {
+			name = strings.ToLower(name)
+			if _, ok := c.names[name]; !ok {
+				c.names[name] = cert
+			}
+		}
+	}
+	c.mu.Unlock()
+}
+
+// reload reloads the changed certificates, the errors are passed to the onError, if not nil, and the previous certificates are kept
+func (c *certStore) reload(onError func(error)) error {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	var firstErr error
+	changed := false
+	for i := range c.files {
+		ok, err := c.load(i)
+		if err != nil {
+			if onError != nil {
+				onError(err)
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		changed = changed || ok
+	}
+	if changed {
+		c.index()
+	}
+	return firstErr
+}
+
+// watch reloads the changed certificates at every interval, until the stop
+func (c *certStore) watch(interval time.Duration, onError func(error)) {
+	c.stop = make(chan struct{})
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.reload(onError)
+			case <-stop:
+				return
+			}
+		}
+	}(c.stop)
+}
+
+// close stops the watch, if any
+func (c *certStore) close() {
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+}
+
+// getCertificate is the tls.Config's GetCertificate, it selects the certificate by the server name of the client
+func (c *certStore) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	name := strings.TrimSuffix(strings.ToLower(hello.ServerName), ".")
+	if cert, ok := c.names[name]; ok {
+		return cert, nil
+	}
+	// *.example.com matches the sub.example.com
+	if idx := strings.IndexByte(name, '.'); idx > 0 {
+		if cert, ok := c.names["*"+name[idx:]]; ok {
+			return cert, nil
+		}
+	}
+	return c.certs[0], nil
+}
+
+// latestModTime returns the latest modification time of the files
+func latestModTime(files ...string) (modTime time.Time) {
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil && info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	return
+}
+
+// newTLSConfig returns the tls.Config of the server's Config, with the certificates and the client CAs loaded
+func (s *Server) newTLSConfig() (*tls.Config, error) {
+	opt := s.Config.TLS
+	if opt == nil {
+		opt = &TLSConfig{}
+	}
+
+	var files []Certificate
+	if s.Config.CertFile != "" && s.Config.KeyFile != "" {
+		files = append(files, Certificate{CertFile: s.Config.CertFile, KeyFile: s.Config.KeyFile})
+	}
+	files = append(files, opt.Certificates...)
+	if len(files) == 0 {
+		return nil, ErrServerTLSOptionsMissing.Return()
+	}
+
+	certs, err := newCertStore(files)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		GetCertificate: certs.getCertificate,
+		MinVersion:     opt.MinVersion,
+		CipherSuites:   opt.CipherSuites,
+		ClientAuth:     opt.ClientAuth,
+		NextProtos:     []string{"http/1.1"},
+	}
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+
+	if opt.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(opt.ClientCAFile)
+		if err != nil {
+			return nil, ErrServerTLSClientCA.Format(opt.ClientCAFile, err.Error())
+		}
+		cfg.ClientCAs = x509.NewCertPool()
+		if !cfg.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, ErrServerTLSClientCA.Format(opt.ClientCAFile, "no pem encoded certificates")
+		}
+		if cfg.ClientAuth == tls.NoClientCert {
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	if opt.ReloadInterval > 0 {
+		certs.watch(opt.ReloadInterval, opt.ReloadError)
+	}
+	s.certs = certs
+	return cfg, nil
+}
+
+// ReloadCertificates reloads the certificates of the tls server whose files are changed, i.e on SIGHUP,
+// the new connections use the new certificates. If a certificate cannot be loaded the previous is kept and the error is returned.
+// It returns ErrServerNoReloadableCertificates if the server is not started with a Config.TLS
+func (s *Server) ReloadCertificates() error {
+	if s.certs == nil {
+		return ErrServerNoReloadableCertificates.Return()
+	}
+	return s.certs.reload(nil)
+}
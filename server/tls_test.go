@@ -0,0 +1,310 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS BE LIABLE FOR ANY
+// DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// testKeyPair a certificate, its key and their pem encoded files
+type testKeyPair struct {
+	cert  *x509.Certificate
+	key   *ecdsa.PrivateKey
+	files Certificate
+}
+
+// newTestKeyPair creates a certificate of the DNS names, signed by the parent or self-signed if the parent is nil,
+// and writes its files, name.crt and name.key, to the dir
+func newTestKeyPair(t *testing.T, dir string, name string, serial int64, parent *testKeyPair, dnsNames ...string) *testKeyPair {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              dnsNames,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := Certificate{CertFile: filepath.Join(dir, name+".crt"), KeyFile: filepath.Join(dir, name+".key")}
+	writeTestFile(t, files.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	writeTestFile(t, files.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+	return &testKeyPair{cert: cert, key: key, files: files}
+}
+
+// writeTestFile writes the file with a modification time later than its previous one, so the reload sees the change
+func writeTestFile(t *testing.T, filename string, contents []byte) {
+	t.Helper()
+	modTime := time.Now()
+	if info, err := os.Stat(filename); err == nil && !modTime.After(info.ModTime()) {
+		modTime = info.ModTime().Add(time.Second)
+	}
+	if err := ioutil.WriteFile(filename, contents, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filename, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestTLSServer(t *testing.T, opt *TLSConfig) *Server {
+	t.Helper()
+	s := New(Config{ListeningAddr: "127.0.0.1:0", TLS: opt})
+	// the rejected handshakes are expected
+	s.Server.Logger = log.New(ioutil.Discard, "", 0)
+	s.SetHandler(func(reqCtx *fasthttp.RequestCtx) {
+		reqCtx.WriteString("hello")
+		if state := reqCtx.TLSConnectionState(); state != nil && len(state.VerifiedChains) > 0 {
+			reqCtx.WriteString(" " + state.VerifiedChains[0][0].Subject.CommonName)
+		}
+	})
+	if err := s.OpenServer(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// serverCertificate connects to the server with the server name and returns the certificate which the server sent
+func serverCertificate(t *testing.T, s *Server, serverName string) *x509.Certificate {
+	t.Helper()
+	conn, err := tls.Dial("tcp", s.Listener().Addr().String(), &tls.Config{ServerName: serverName, InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0]
+}
+
+func TestTLSCertificatesSNI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	def := newTestKeyPair(t, dir, "default", 1, nil, "default.com")
+	com := newTestKeyPair(t, dir, "example", 2, nil, "example.com", "www.example.com")
+	org := newTestKeyPair(t, dir, "wildcard", 3, nil, "*.example.org")
+
+	s := newTestTLSServer(t, &TLSConfig{Certificates: []Certificate{def.files, com.files, org.files}})
+	defer s.CloseServer()
+	if !s.IsSecure() {
+		t.Fatal("expected a tls server")
+	}
+
+	tests := map[string]int64{
+		"example.com":     2,
+		"WWW.Example.com": 2,
+		"api.example.org": 3,
+		"example.org":     1,
+		"a.b.example.org": 1,
+		"unknown.com":     1,
+		"":                1,
+	}
+	for serverName, serial := range tests {
+		if cert := serverCertificate(t, s, serverName); cert.SerialNumber.Int64() != serial {
+			t.Fatalf("%q: expected the certificate %d but got %d", serverName, serial, cert.SerialNumber.Int64())
+		}
+	}
+}
+
+func TestTLSCertificatesIndex(t *testing.T) {
+	// the DNSNames have room for one more name, the CommonName shouldn't be written there
+	dnsNames := make([]string, 1, 2)
+	dnsNames[0] = "example.com"
+	leaf := &x509.Certificate{DNSNames: dnsNames, Subject: pkix.Name{CommonName: "www.example.com"}}
+	cert := &tls.Certificate{Leaf: leaf}
+
+	c := &certStore{certs: []*tls.Certificate{cert}}
+	c.index()
+	if c.names["example.com"] != cert || c.names["www.example.com"] != cert {
+		t.Fatal("expected the certificate by its DNS names and its common name")
+	}
+	if len(leaf.DNSNames) != 1 || dnsNames[:2][1] != "" {
+		t.Fatalf("expected the DNS names of the certificate to be kept as they are but got %v", dnsNames[:2])
+	}
+}
+
+func TestTLSReloadCertificates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	newTestKeyPair(t, dir, "example", 1, nil, "example.com")
+	s := newTestTLSServer(t, &TLSConfig{Certificates: []Certificate{{CertFile: filepath.Join(dir, "example.crt"), KeyFile: filepath.Join(dir, "example.key")}}})
+	defer s.CloseServer()
+
+	// nothing changed
+	if err := s.ReloadCertificates(); err != nil {
+		t.Fatal(err)
+	}
+	if serial := serverCertificate(t, s, "example.com").SerialNumber.Int64(); serial != 1 {
+		t.Fatalf("expected the certificate 1 but got %d", serial)
+	}
+
+	// the renewed certificate is used by the new connections
+	newTestKeyPair(t, dir, "example", 2, nil, "example.com")
+	if err := s.ReloadCertificates(); err != nil {
+		t.Fatal(err)
+	}
+	if serial := serverCertificate(t, s, "example.com").SerialNumber.Int64(); serial != 2 {
+		t.Fatalf("expected the reloaded certificate 2 but got %d", serial)
+	}
+
+	// a broken certificate is reported and the previous one is kept
+	writeTestFile(t, filepath.Join(dir, "example.crt"), []byte("broken"))
+	if err := s.ReloadCertificates(); err == nil {
+		t.Fatal("expected the error of the broken certificate")
+	}
+	if serial := serverCertificate(t, s, "example.com").SerialNumber.Int64(); serial != 2 {
+		t.Fatalf("expected the previous certificate 2 but got %d", serial)
+	}
+
+	// only the servers of a TLSConfig have reloadable certificates
+	if err := New(Config{ListeningAddr: "127.0.0.1:0"}).ReloadCertificates(); err == nil || err.Error() != ErrServerNoReloadableCertificates.Error() {
+		t.Fatalf("expected the ErrServerNoReloadableCertificates but got %v", err)
+	}
+}
+
+func TestTLSReloadInterval(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pair := newTestKeyPair(t, dir, "example", 1, nil, "example.com")
+	reloadErrors := make(chan error, 10)
+	s := newTestTLSServer(t, &TLSConfig{
+		Certificates:   []Certificate{pair.files},
+		ReloadInterval: 10 * time.Millisecond,
+		ReloadError:    func(err error) { reloadErrors <- err },
+	})
+	defer s.CloseServer()
+
+	writeTestFile(t, pair.files.CertFile, []byte("broken"))
+	select {
+	case <-reloadErrors:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the ReloadError of the broken certificate")
+	}
+
+	newTestKeyPair(t, dir, "example", 2, nil, "example.com")
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if serial := serverCertificate(t, s, "example.com").SerialNumber.Int64(); serial == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the certificate to be reloaded by the interval")
+		}
+	}
+}
+
+func TestTLSClientCertificates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestKeyPair(t, dir, "ca", 1, nil)
+	serverPair := newTestKeyPair(t, dir, "server", 2, ca, "example.com")
+	clientPair := newTestKeyPair(t, dir, "client", 3, ca)
+	strangerPair := newTestKeyPair(t, dir, "stranger", 4, nil)
+
+	s := newTestTLSServer(t, &TLSConfig{Certificates: []Certificate{serverPair.files}, ClientCAFile: ca.files.CertFile})
+	defer s.CloseServer()
+
+	get := func(pair *testKeyPair) (string, error) {
+		clientCfg := &tls.Config{ServerName: "example.com", RootCAs: x509.NewCertPool()}
+		clientCfg.RootCAs.AddCert(ca.cert)
+		if pair != nil {
+			cert, err := tls.LoadX509KeyPair(pair.files.CertFile, pair.files.KeyFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			clientCfg.Certificates = []tls.Certificate{cert}
+		}
+		c := &fasthttp.Client{TLSConfig: clientCfg}
+		statusCode, body, err := c.Get(nil, "https://"+s.Listener().Addr().String()+"/")
+		if err == nil && statusCode != fasthttp.StatusOK {
+			t.Fatalf("expected status code 200 but got %d", statusCode)
+		}
+		return string(body), err
+	}
+
+	if body, err := get(clientPair); err != nil || body != "hello client" {
+		t.Fatalf("expected the verified client but got %q, %v", body, err)
+	}
+	if _, err := get(nil); err == nil {
+		t.Fatal("expected the client without a certificate to be rejected")
+	}
+	if _, err := get(strangerPair); err == nil {
+		t.Fatal("expected the client with an unknown certificate to be rejected")
+	}
+}
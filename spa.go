@@ -0,0 +1,199 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/kataras/iris/render"
+)
+
+const (
+	// DefaultSPAIndex is the default index file of the SPA, it's served by every unknown path
+	DefaultSPAIndex = "index.html"
+	// DefaultSPAIndexCacheControl is the default Cache-Control of the SPA's index, it's revalidated on every request
+	// so a new deployment is picked up immediately
+	DefaultSPAIndexCacheControl = "no-cache"
+)
+
+// DefaultSPAHashedPattern matches the hashed (fingerprinted) file names of the bundlers, i.e "main.3f2a1b9c.js" or "app-3f2a1b9c0d.css"
+var DefaultSPAHashedPattern = regexp.MustCompile(`[.\-][0-9a-fA-F]{8,}\.[^./]+$`)
+
+// SPAOptions the options of the Party.SPA, all fields are optional
+type SPAOptions struct {
+	// FileSystem serves the files of the application from a http.FileSystem instead of the system directory, if not nil
+	FileSystem http.FileSystem
+	// Index the file which is served by the unknown paths, relative to the directory
+	// Default is "index.html"
+	Index string
+	// Exclude the request path prefixes which are never served by the index, i.e "/api", they are not found (404) instead
+	Exclude []string
+	// IndexCacheControl the Cache-Control header of the index
+	// Default is "no-cache"
+	IndexCacheControl string
+	// HashedCacheControl the Cache-Control header of the hashed files, which match the HashedPattern
+	// Default is the AssetCacheControl, "public, max-age=31536000, immutable"
+	HashedCacheControl string
+	// HashedPattern matches the base names of the hashed files
+	// Default is the DefaultSPAHashedPattern
+	HashedPattern *regexp.Regexp
+	// DisablePrecompressed if true the pre-compressed .br and .gz siblings of the files are not served
+	// Default is false
+	DisablePrecompressed bool
+}
+
+// precompressedEncodings the encodings of the pre-compressed siblings, by preference
+var precompressedEncodings = []string{render.EncodingBrotli, render.EncodingGzip}
+
+// precompressedExtensions the extensions of the pre-compressed siblings per encoding
+var precompressedExtensions = map[string]string{render.EncodingBrotli: ".br", render.EncodingGzip: ".gz"}
+
+// SPAHandlerFunc returns a HandlerFunc which serves a single-page application from a http.FileSystem,
+// the request path, without the prefix, is the name of the file.
+// The existing files are served, the unknown paths without an extension are served by the index,
+// the unknown paths with an extension (i.e a missing .js) and the excluded prefixes are not found (404)
+func SPAHandlerFunc(prefix string, fs http.FileSystem, options ...SPAOptions) HandlerFunc {
+	opt := SPAOptions{}
+	if len(options) > 0 {
+		opt = options[0]
+	}
+	if opt.Index == "" {
+		opt.Index = DefaultSPAIndex
+	}
+	if opt.IndexCacheControl == "" {
+		opt.IndexCacheControl = DefaultSPAIndexCacheControl
+	}
+	if opt.HashedCacheControl == "" {
+		opt.HashedCacheControl = AssetCacheControl
+	}
+	if opt.HashedPattern == nil {
+		opt.HashedPattern = DefaultSPAHashedPattern
+	}
+	prefix = strings.TrimSuffix(prefix, Slash)
+	index := path.Clean(Slash + opt.Index)
+
+	return func(ctx *Context) {
+		reqPath := ctx.PathString()
+		for _, excluded := range opt.Exclude {
+			excluded = strings.TrimSuffix(excluded, Slash)
+			if reqPath == excluded || strings.HasPrefix(reqPath, excluded+Slash) {
+				ctx.NotFound()
+				return
+			}
+		}
+
+		name := path.Clean(Slash + strings.TrimPrefix(reqPath, prefix))
+		if info, err := statFile(fs, name); err != nil || info.IsDir() {
+			if err != nil && path.Ext(name) != "" {
+				ctx.NotFound()
+				return
+			}
+			name = index
+		}
+
+		if name == index {
+			ctx.Response.Header.Set("Cache-Control", opt.IndexCacheControl)
+		} else if opt.HashedPattern.MatchString(path.Base(name)) {
+			ctx.Response.Header.Set("Cache-Control", opt.HashedCacheControl)
+		}
+
+		if !opt.DisablePrecompressed && serveSPAPrecompressed(ctx, fs, name) {
+			return
+		}
+
+		f, info, err := openFile(fs, name)
+		if err != nil {
+			ctx.NotFound()
+			return
+		}
+		defer f.Close()
+		ctx.HandleError(ctx.ServeContent(f, info.Name(), info.ModTime()))
+	}
+}
+
+// serveSPAPrecompressed serves the .br or .gz sibling of the file, by the Accept-Encoding of the client,
+// returns false if there is no sibling which the client accepts
+func serveSPAPrecompressed(ctx *Context, fs http.FileSystem, name string) bool {
+	render.AddVary(&ctx.Response.Header, "Accept-Encoding")
+	acceptEncoding := ctx.RequestHeader("Accept-Encoding")
+	if acceptEncoding == "" {
+		return false
+	}
+
+	// negotiate between the siblings which exist, so a missing .br falls back to the .gz
+	var encodings []string
+	for _, encoding := range precompressedEncodings {
+		if info, err := statFile(fs, name+precompressedExtensions[encoding]); err == nil && !info.IsDir() {
+			encodings = append(encodings, encoding)
+		}
+	}
+	encoding := render.NegotiateEncoding(acceptEncoding, encodings)
+	if encoding == "" {
+		return false
+	}
+
+	f, info, err := openFile(fs, name+precompressedExtensions[encoding])
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	ctx.Response.Header.Set("Content-Encoding", encoding)
+	// the content type is the original file's
+	ctx.HandleError(ctx.ServeContent(f, path.Base(name), info.ModTime()))
+	return true
+}
+
+// SPA registers a route which serves a single-page application, the existing files are served
+// and every other GET path under the relative path, which has no extension, is served by the index.html, so the deep links of the client-side router work.
+// The routes which are registered to the same paths, i.e "/api", are served as usual and the Exclude prefixes are not found (404).
+// The pre-compressed .br and .gz siblings of the files are served when the client accepts them,
+// the index is served with "Cache-Control: no-cache" and the hashed files, i.e "main.3f2a1b9c.js", with a far-future Cache-Control.
+// accepts three parameters
+// first parameter is the request url path (string)
+// second parameter is the system directory (string), it's ignored if the options' FileSystem is not nil
+// third parameter is optional, the options (SPAOptions)
+func (p *GardenParty) SPA(relative string, systemPath string, options ...SPAOptions) {
+	if relative[len(relative)-1] != SlashByte {
+		relative += "/"
+	}
+
+	var fs http.FileSystem = http.Dir(systemPath)
+	if len(options) > 0 && options[0].FileSystem != nil {
+		fs = options[0].FileSystem
+	}
+
+	prefix := absPath(p.relativePath, relative)
+	h := SPAHandlerFunc(prefix, fs, options...)
+	p.Get(relative+"*filepath", h)
+	// the deep links which are not matched by the route, because of the other routes of the same path, are served by the fallback
+	p.station.router.addFallback(prefix, p.routeMiddleware(Middleware{h}))
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package iris
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris/utils"
+)
+
+func newTestSPAIris(relative string) *Iris {
+	s := newTestIris()
+	s.Get("/api/users", func(ctx *Context) {
+		ctx.Text(StatusOK, "users")
+	})
+	s.SPA(relative, "", SPAOptions{
+		FileSystem: utils.NewMapFS(map[string][]byte{
+			"index.html":          []byte("<app></app>"),
+			"main.3f2a1b9c.js":    []byte("run()"),
+			"main.3f2a1b9c.js.gz": []byte("gzipped run()"),
+			"style.css":           []byte("body {}"),
+			"img/logo.png":        []byte("png"),
+		}),
+		Exclude: []string{"/api"},
+	})
+	testServe(s)
+	return s
+}
+
+func TestSPA(t *testing.T) {
+	for _, prefix := range []string{"/", "/app"} {
+		s := newTestSPAIris(prefix)
+		base := strings.TrimSuffix(prefix, "/")
+
+		tests := []struct {
+			path         string
+			status       int
+			body         string
+			cacheControl string
+		}{
+			{base + "/", StatusOK, "<app></app>", DefaultSPAIndexCacheControl},
+			{base + "/dashboard/settings", StatusOK, "<app></app>", DefaultSPAIndexCacheControl},
+			{base + "/img", StatusOK, "<app></app>", DefaultSPAIndexCacheControl},
+			{base + "/main.3f2a1b9c.js", StatusOK, "run()", AssetCacheControl},
+			{base + "/style.css", StatusOK, "body {}", ""},
+			{base + "/img/logo.png", StatusOK, "png", ""},
+			{base + "/missing.js", StatusNotFound, "404 not found", ""},
+			{"/api/users", StatusOK, "users", ""},
+			{"/api/missing", StatusNotFound, "404 not found", ""},
+		}
+		for _, tt := range tests {
+			reqCtx := testRequest(s, MethodGet, tt.path, "")
+			expectResponse(t, reqCtx, tt.status, tt.body)
+			if cacheControl := string(reqCtx.Response.Header.Peek("Cache-Control")); cacheControl != tt.cacheControl {
+				t.Fatalf("%s: expected the Cache-Control %q but got %q", tt.path, tt.cacheControl, cacheControl)
+			}
+		}
+
+		// only the GET and HEAD requests are served by the index
+		expectResponse(t, testRequest(s, MethodPost, base+"/dashboard", ""), StatusNotFound, "404 not found")
+	}
+}
+
+func TestSPAPrecompressed(t *testing.T) {
+	s := newTestSPAIris("/")
+
+	reqCtx := testRequest(s, MethodGet, "/main.3f2a1b9c.js", "", "Accept-Encoding", "br, gzip")
+	expectResponse(t, reqCtx, StatusOK, "gzipped run()")
+	if encoding := string(reqCtx.Response.Header.Peek("Content-Encoding")); encoding != "gzip" {
+		t.Fatalf("expected the gzip Content-Encoding but got %q", encoding)
+	}
+	if contentType := string(reqCtx.Response.Header.ContentType()); !strings.Contains(contentType, "javascript") {
+		t.Fatalf("expected the content type of the original file but got %q", contentType)
+	}
+	if vary := string(reqCtx.Response.Header.Peek("Vary")); vary != "Accept-Encoding" {
+		t.Fatalf("expected the Vary: Accept-Encoding but got %q", vary)
+	}
+
+	// the files without a pre-compressed sibling are sent as they are
+	reqCtx = testRequest(s, MethodGet, "/style.css", "", "Accept-Encoding", "gzip")
+	expectResponse(t, reqCtx, StatusOK, "body {}")
+	if encoding := reqCtx.Response.Header.Peek("Content-Encoding"); len(encoding) > 0 {
+		t.Fatalf("expected no Content-Encoding but got %q", encoding)
+	}
+}
+
+func TestSPAHashedPattern(t *testing.T) {
+	tests := map[string]bool{
+		"main.3f2a1b9c.js":      true,
+		"app-3f2a1b9c0d.css":    true,
+		"chunk.0123456789ab.js": true,
+		"main.js":               false,
+		"main.3f2a.js":          false,
+		"jquery-3.1.1.min.js":   false,
+	}
+	for name, expected := range tests {
+		if got := DefaultSPAHashedPattern.MatchString(name); got != expected {
+			t.Fatalf("%s: expected %v but got %v", name, expected, got)
+		}
+	}
+}
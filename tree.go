@@ -126,16 +126,24 @@ func newTree(station *Iris, method string, theRoot *Branch, domain string, hosts
 }
 
 // serve serves the route
-func (_tree *tree) serve(reqCtx *fasthttp.RequestCtx, path string) bool {
+// the done handlers and the OnEnd funcs are executed after the route's handlers or the path correction's redirect
+func (_tree *tree) serve(reqCtx *fasthttp.RequestCtx, path string) (served bool) {
 	ctx := _tree.pool.Get().(*Context)
 	ctx.Reset(reqCtx)
+	defer func() {
+		// when nothing is served the router emits the not found with an other Context, which ends itself
+		if served {
+			ctx.end()
+		}
+		_tree.pool.Put(ctx)
+	}()
+
 	middleware, params, mustRedirect := _tree.rootBranch.GetBranch(path, ctx.Params) // pass the parameters here for 0 allocation
 	if middleware != nil {
 		ctx.Params = params
 		ctx.middleware = middleware
 		//ctx.Request.Header.SetUserAgentBytes(DefaultUserAgent)
 		ctx.Do()
-		_tree.pool.Put(ctx)
 		return true
 	} else if mustRedirect && _tree.station.Config.PathCorrection && !bytes.Equal(reqCtx.Method(), MethodConnectBytes) {
 
@@ -151,6 +159,8 @@ func (_tree *tree) serve(reqCtx *fasthttp.RequestCtx, path string) bool {
 				reqPath = reqPath + "/"
 			}
 
+			// no route is matched, the done handlers of the station are executed
+			ctx.done = _tree.station.router.GardenParty.done
 			ctx.Request.URI().SetPath(reqPath)
 			urlToRedirect := utils.BytesToString(ctx.Request.RequestURI())
 
@@ -160,13 +170,11 @@ func (_tree *tree) serve(reqCtx *fasthttp.RequestCtx, path string) bool {
 			// Shouldn't send the response for POST or HEAD; that leaves GET.
 			if _tree.method == MethodGet {
 				note := "<a href=\"" + utils.HtmlEscape(urlToRedirect) + "\">Moved Permanently</a>.\n"
-				ctx.Write(note)
+				ctx.Write("%s", note)
 			}
-			_tree.pool.Put(ctx)
 			return true
 		}
 	}
 
-	_tree.pool.Put(ctx)
 	return false
 }
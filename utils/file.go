@@ -284,38 +284,74 @@ func GetParentDir(targetDirectory string) string {
 	return parentDirectory
 }
 
+// watchDelay is the time which the WatchDirectoryChanges waits, after a change, for more changes before it calls the callback
+// editors are doing more than one file operations on save
+const watchDelay = 100 * time.Millisecond
+
 // WatchDirectoryChanges watches for directory changes and calls the 'evt' callback parameter
+// the rootPath is watched recursively, sub directories which are created after the call are watched too
+// changes which happen close to each other are grouped, the callback receives the last changed file
+// returns a func which stops the watcher, or nil if the watcher can't be started or it can't watch the rootPath or one of its sub directories,
+// i.e the rootPath doesn't exist or the limit of the inotify watchers (max_user_watches) is reached, so the caller can fall back to something else
 // 3-BSD License for package fsnotify/fsnotify
 // Copyright (c) 2012 The Go Authors. All rights reserved.
 // Copyright (c) 2012 fsnotify Authors. All rights reserved.
-func WatchDirectoryChanges(rootPath string, evt func(filename string), logger ...*logger.Logger) {
+func WatchDirectoryChanges(rootPath string, evt func(filename string), logger ...*logger.Logger) func() {
 	watcher, err := fsnotify.NewWatcher()
 
 	if err != nil {
 		if len(logger) > 0 {
 			errors.Printf(logger[0], err)
 		}
-		return
+		return nil
+	}
+
+	// watchDir adds a directory and all of its sub directories to the watcher, it stops on the first error
+	watchDir := func(dir string) error {
+		return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && info.IsDir() {
+				err = watcher.Add(path)
+			}
+			return err
+		})
+	}
+
+	if err = watchDir(rootPath); err != nil {
+		watcher.Close()
+		if len(logger) > 0 {
+			errors.Printf(logger[0], err)
+		}
+		return nil
 	}
 
 	go func() {
-		var lastChange = time.Now()
-		var i = 0
+		var changed string
+		timer := time.NewTimer(watchDelay)
+		timer.Stop()
 		for {
 			select {
-			case event := <-watcher.Events:
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					//this is received two times, the last time is the real changed file, so
-					i++
-					if i%2 == 0 {
-						if time.Now().After(lastChange.Add(time.Duration(1) * time.Second)) {
-							lastChange = time.Now()
-							evt(event.Name)
+			case event, ok := <-watcher.Events:
+				if !ok {
+					timer.Stop()
+					return
+				}
+				if event.Op&fsnotify.Create == fsnotify.Create {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						if err = watchDir(event.Name); err != nil && len(logger) > 0 {
+							errors.Printf(logger[0], err)
 						}
 					}
-
 				}
-			case err := <-watcher.Errors:
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+					changed = event.Name
+					timer.Reset(watchDelay)
+				}
+			case <-timer.C:
+				evt(changed)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
 				if len(logger) > 0 {
 					errors.Printf(logger[0], err)
 				}
@@ -323,11 +359,7 @@ func WatchDirectoryChanges(rootPath string, evt func(filename string), logger ..
 		}
 	}()
 
-	err = watcher.Add(rootPath)
-	if err != nil {
-		if len(logger) > 0 {
-			errors.Printf(logger[0], err)
-		}
+	return func() {
+		watcher.Close()
 	}
-
 }
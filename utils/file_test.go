@@ -0,0 +1,107 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchDirectoryChanges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	changes := make(chan string, 10)
+	stop := WatchDirectoryChanges(dir, func(filename string) {
+		changes <- filename
+	})
+	if stop == nil {
+		t.Skip("the watcher can't be started on this system")
+	}
+	defer stop()
+
+	expectChange := func(filename string) {
+		t.Helper()
+		select {
+		case changed := <-changes:
+			if changed != filename {
+				t.Fatalf("expected a change of %s but got %s", filename, changed)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected a change of %s", filename)
+		}
+	}
+
+	filename := filepath.Join(dir, "index.html")
+	if err = ioutil.WriteFile(filename, []byte("index"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectChange(filename)
+
+	// the directories which are created after the call are watched too
+	sub := filepath.Join(dir, "users")
+	if err = os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	expectChange(sub)
+
+	filename = filepath.Join(sub, "show.html")
+	if err = ioutil.WriteFile(filename, []byte("show"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectChange(filename)
+
+	// the changes after the stop are not reported
+	stop()
+	ioutil.WriteFile(filepath.Join(dir, "stopped.html"), []byte("stopped"), 0644)
+	select {
+	case changed := <-changes:
+		t.Fatalf("unexpected change of %s after the stop", changed)
+	case <-time.After(3 * watchDelay):
+	}
+}
+
+func TestWatchDirectoryChangesMissing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.RemoveAll(dir)
+
+	// a directory which can't be watched returns no stop func, so the caller falls back to something else
+	if stop := WatchDirectoryChanges(dir, func(string) {}); stop != nil {
+		stop()
+		t.Fatal("expected no stop func for a directory which can't be watched")
+	}
+}
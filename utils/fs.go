@@ -0,0 +1,296 @@
+// Copyright (c) 2016, Gerasimos Maropoulos
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//	  this list of conditions and the following disclaimer
+//    in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse
+//    or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER AND CONTRIBUTOR, GERASIMOS MAROPOULOS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// The file systems of this file implement the net/http's http.FileSystem,
+// the same interface which the http.Dir implements for the OS directories,
+// they are used to embed the templates, the static files and the locale files into the binary.
+
+// memEntry is a file or a directory of a memFS, it's the os.FileInfo of it too
+type memEntry struct {
+	name     string
+	size     int64
+	modTime  time.Time
+	contents func() ([]byte, error) // nil for the directories
+	children []*memEntry
+}
+
+func (e *memEntry) Name() string       { return e.name }
+func (e *memEntry) Size() int64        { return e.size }
+func (e *memEntry) ModTime() time.Time { return e.modTime }
+func (e *memEntry) IsDir() bool        { return e.contents == nil }
+func (e *memEntry) Sys() interface{}   { return nil }
+func (e *memEntry) Mode() os.FileMode {
+	if e.IsDir() {
+		return os.ModeDir | 0555
+	}
+	return 0444
+}
+
+// memFS is a read-only http.FileSystem of which the entries are known in advance, the contents are read on Open
+type memFS struct {
+	entries map[string]*memEntry
+	modTime time.Time
+}
+
+func newMemFS(modTime time.Time) *memFS {
+	return &memFS{
+		entries: map[string]*memEntry{"/": {name: "/", modTime: modTime}},
+		modTime: modTime,
+	}
+}
+
+// add adds a file and its parent directories
+func (fs *memFS) add(name string, size int64, modTime time.Time, contents func() ([]byte, error)) {
+	name = path.Clean("/" + name)
+	if _, exists := fs.entries[name]; exists || name == "/" {
+		return
+	}
+	entry := &memEntry{name: path.Base(name), size: size, modTime: modTime, contents: contents}
+	fs.entries[name] = entry
+
+	for dir := path.Dir(name); ; dir = path.Dir(dir) {
+		parent, exists := fs.entries[dir]
+		if !exists {
+			parent = &memEntry{name: path.Base(dir), modTime: fs.modTime}
+			fs.entries[dir] = parent
+		}
+		parent.children = append(parent.children, entry)
+		if exists {
+			break
+		}
+		entry = parent
+	}
+}
+
+// Open opens a file or a directory, the name is slash-separated and relative to the root of the file system
+func (fs *memFS) Open(name string) (http.File, error) {
+	entry, ok := fs.entries[path.Clean("/"+name)]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	f := &memFile{entry: entry}
+	if entry.IsDir() {
+		f.Reader = bytes.NewReader(nil)
+		f.children = make([]os.FileInfo, len(entry.children))
+		for i, child := range entry.children {
+			f.children[i] = child
+		}
+		sort.Sort(byName(f.children))
+		return f, nil
+	}
+
+	contents, err := entry.contents()
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	f.Reader = bytes.NewReader(contents)
+	return f, nil
+}
+
+type byName []os.FileInfo
+
+func (s byName) Len() int           { return len(s) }
+func (s byName) Less(i, j int) bool { return s[i].Name() < s[j].Name() }
+func (s byName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// memFile is the http.File of a memFS
+type memFile struct {
+	*bytes.Reader
+	entry    *memEntry
+	children []os.FileInfo
+}
+
+func (f *memFile) Close() error { return nil }
+
+func (f *memFile) Stat() (os.FileInfo, error) { return f.entry, nil }
+
+// Readdir returns the next count entries of a directory, or all the remaining if count <= 0, same as the os.File's Readdir
+func (f *memFile) Readdir(count int) ([]os.FileInfo, error) {
+	if !f.entry.IsDir() {
+		return nil, &os.PathError{Op: "readdir", Path: f.entry.name, Err: os.ErrInvalid}
+	}
+	if count <= 0 {
+		children := f.children
+		f.children = nil
+		return children, nil
+	}
+	if len(f.children) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(f.children) {
+		count = len(f.children)
+	}
+	children := f.children[:count]
+	f.children = f.children[count:]
+	return children, nil
+}
+
+// NewMapFS returns a read-only http.FileSystem of the files of the map,
+// the keys are the slash-separated paths of the files, i.e "templates/index.html", and the values are their contents.
+// The directories are implied by the paths.
+func NewMapFS(files map[string][]byte) http.FileSystem {
+	now := time.Now()
+	fs := newMemFS(now)
+	for name, contents := range files {
+		contents := contents
+		fs.add(name, int64(len(contents)), now, func() ([]byte, error) { return contents, nil })
+	}
+	return fs
+}
+
+// NewZipFS returns a read-only http.FileSystem of the files of a zip archive,
+// the files are decompressed on Open.
+// Use it with an archive which is embedded into the binary, i.e zip.NewReader(bytes.NewReader(data), int64(len(data)))
+func NewZipFS(r *zip.Reader) http.FileSystem {
+	fs := newMemFS(time.Now())
+	for _, zf := range r.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		zf := zf
+		fs.add(zf.Name, int64(zf.UncompressedSize64), zf.ModTime(), func() ([]byte, error) {
+			rc, err := zf.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return ioutil.ReadAll(rc)
+		})
+	}
+	return fs
+}
+
+// ZipFS opens a zip archive and returns a read-only http.FileSystem of its files, look NewZipFS.
+// The archive stays open for the rest of the program's life.
+func ZipFS(archive string) (http.FileSystem, error) {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	return NewZipFS(&r.Reader), nil
+}
+
+// NewAssetFS returns a read-only http.FileSystem of the files of an asset func and its names, i.e the go-bindata's Asset and AssetNames
+func NewAssetFS(asset func(name string) ([]byte, error), assetNames func() []string) http.FileSystem {
+	now := time.Now()
+	fs := newMemFS(now)
+	for _, name := range assetNames() {
+		name := name
+		fs.add(name, 0, now, func() ([]byte, error) { return asset(name) })
+	}
+	return fs
+}
+
+// subFS is the http.FileSystem of a directory of another http.FileSystem
+type subFS struct {
+	fs  http.FileSystem
+	dir string
+}
+
+func (s subFS) Open(name string) (http.File, error) {
+	// clean the name first, so it can't go outside of the directory
+	return s.fs.Open(path.Join(s.dir, path.Clean("/"+name)))
+}
+
+// SubFS returns the http.FileSystem of a directory of the fs, i.e the "public" directory of a zip archive
+func SubFS(fs http.FileSystem, dir string) http.FileSystem {
+	return subFS{fs: fs, dir: path.Clean("/" + dir)}
+}
+
+// ReadFile reads the whole file of a http.FileSystem
+func ReadFile(fs http.FileSystem, name string) ([]byte, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+// WalkFS walks the files and the directories of a http.FileSystem under the root, in lexical order, same as the filepath.Walk.
+// The paths which are passed to the walkFn are slash-separated and begin with the root.
+// If the walkFn returns filepath.SkipDir for a directory, the directory is skipped.
+func WalkFS(fs http.FileSystem, root string, walkFn func(path string, info os.FileInfo, err error) error) error {
+	root = path.Clean("/" + root)
+	f, err := fs.Open(root)
+	if err != nil {
+		return walkFn(root, nil, err)
+	}
+	info, err := f.Stat()
+	f.Close()
+	if err != nil {
+		return walkFn(root, nil, err)
+	}
+	err = walkFS(fs, root, info, walkFn)
+	if err == filepath.SkipDir {
+		return nil
+	}
+	return err
+}
+
+func walkFS(fs http.FileSystem, name string, info os.FileInfo, walkFn func(path string, info os.FileInfo, err error) error) error {
+	if err := walkFn(name, info, nil); err != nil || !info.IsDir() {
+		return err
+	}
+
+	f, err := fs.Open(name)
+	if err != nil {
+		return walkFn(name, info, err)
+	}
+	children, err := f.Readdir(-1)
+	f.Close()
+	if err != nil {
+		return walkFn(name, info, err)
+	}
+	sort.Sort(byName(children))
+
+	for _, child := range children {
+		if err = walkFS(fs, path.Join(name, child.Name()), child, walkFn); err != nil {
+			if err == filepath.SkipDir && child.IsDir() {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}